@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+	redis_cache "pinstack-user-service/internal/infrastructure/outbound/cache/redis"
+	prometheus_metrics "pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+	user_repository "pinstack-user-service/internal/infrastructure/outbound/repository/postgres"
+)
+
+// cachecheck compares user:* cache entries with Postgres and reports entries
+// that disagree. With -repair it deletes them so the service reloads them on
+// the next read. It is safe to run while the service is serving.
+func main() {
+	cfg := config.MustLoad()
+
+	log := logger.New(cfg.Env)
+
+	batchSize := flag.Int64("batch", 500, "Number of keys to request per SCAN call")
+	sampleRate := flag.Float64("sample", 1, "Fraction of keys to check, between 0 and 1")
+	repair := flag.Bool("repair", false, "Delete entries that disagree with the database")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	pool, err := user_repository.NewPool(ctx, user_repository.DSN("postgresql", cfg.Database), cfg.Database)
+	if err != nil {
+		log.Error("Failed to create postgres pool", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	redisClient, err := redis_cache.NewClient(cfg.Redis, log)
+	if err != nil {
+		log.Error("Failed to create Redis client", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Error("Failed to close Redis connection", slog.String("error", err.Error()))
+		}
+	}()
+
+	if !redisClient.Available() {
+		log.Error("Redis is unavailable")
+		os.Exit(1)
+	}
+
+	// The cache is compared against the primary: a lagging replica would
+	// report fresh entries as stale.
+	metrics := prometheus_metrics.NewPrometheusMetricsProvider()
+	router := user_repository.NewRouter(pool, nil, cfg.Database, log, metrics)
+	userRepo := user_repository.NewUserRepository(router, log, metrics)
+
+	stats, err := redis_cache.CheckConsistency(ctx, redisClient, userRepo, redis_cache.CheckOptions{
+		BatchSize:  *batchSize,
+		SampleRate: *sampleRate,
+		Repair:     *repair,
+	}, log)
+	if err != nil {
+		log.Error("Failed to check cache consistency", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	attrs := []any{
+		slog.Bool("repair", *repair),
+		slog.Int("scanned", stats.Scanned),
+		slog.Int("checked", stats.Checked),
+		slog.Int("drifted", stats.Drifted),
+		slog.Int("repaired", stats.Repaired),
+		slog.Int("failed", stats.Failed),
+	}
+	for kind, count := range stats.ByKind {
+		attrs = append(attrs, slog.Int(string(kind), count))
+	}
+	log.Info("Cache consistency check finished", attrs...)
+
+	if stats.Drifted > stats.Repaired {
+		os.Exit(2)
+	}
+}
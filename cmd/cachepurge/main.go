@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+	redis_cache "pinstack-user-service/internal/infrastructure/outbound/cache/redis"
+)
+
+// cachepurge deletes user cache entries left under the key layout used before
+// user keys were hash-tagged, which may still hold password hashes. It is
+// safe to run while the service is serving.
+func main() {
+	cfg := config.MustLoad()
+
+	log := logger.New(cfg.Env)
+
+	batchSize := flag.Int64("batch", 500, "Number of keys to request per SCAN call")
+	dryRun := flag.Bool("dry-run", false, "Only count the legacy entries that would be deleted")
+	flag.Parse()
+
+	redisClient, err := redis_cache.NewClient(cfg.Redis, log)
+	if err != nil {
+		log.Error("Failed to create Redis client", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Error("Failed to close Redis connection", slog.String("error", err.Error()))
+		}
+	}()
+
+	if !redisClient.Available() {
+		log.Error("Redis is unavailable")
+		os.Exit(1)
+	}
+
+	stats, err := redis_cache.PurgePasswordHashes(context.Background(), redisClient, *batchSize, *dryRun, log)
+	if err != nil {
+		log.Error("Failed to purge password hashes from cache", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	log.Info("Cache purge finished",
+		slog.Bool("dry_run", *dryRun),
+		slog.Int("scanned", stats.Scanned),
+		slog.Int("deleted", stats.Deleted),
+		slog.Int("skipped", stats.Skipped))
+}
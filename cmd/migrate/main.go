@@ -7,6 +7,7 @@ import (
 	"pinstack-user-service/internal/infrastructure/config"
 	"pinstack-user-service/internal/infrastructure/logger"
 	"pinstack-user-service/internal/infrastructure/migrator"
+	user_repository "pinstack-user-service/internal/infrastructure/outbound/repository/postgres"
 )
 
 func main() {
@@ -17,8 +18,7 @@ func main() {
 	command := flag.String("command", "up", "Migration command (up/down)")
 	flag.Parse()
 
-	dsn := "postgres://" + cfg.Database.Username + ":" + cfg.Database.Password + "@" +
-		cfg.Database.Host + ":" + cfg.Database.Port + "/" + cfg.Database.DbName + "?sslmode=disable"
+	dsn := user_repository.DSN("postgres", cfg.Database)
 
 	m, err := migrator.NewMigrator(cfg.Database.MigrationsPath, dsn, log)
 	if err != nil {
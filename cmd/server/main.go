@@ -2,17 +2,19 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	user_service "pinstack-user-service/internal/application/service"
+	"pinstack-user-service/internal/domain/ports/output/cache"
 	"pinstack-user-service/internal/infrastructure/config"
 	infra_grpc "pinstack-user-service/internal/infrastructure/inbound/grpc"
 	user_grpc "pinstack-user-service/internal/infrastructure/inbound/grpc/user"
 	metrics_server "pinstack-user-service/internal/infrastructure/inbound/metrics"
 	"pinstack-user-service/internal/infrastructure/logger"
+	local_cache "pinstack-user-service/internal/infrastructure/outbound/cache/local"
 	redis_cache "pinstack-user-service/internal/infrastructure/outbound/cache/redis"
+	"pinstack-user-service/internal/infrastructure/outbound/hasher/argon2id"
 	prometheus_metrics "pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
 	user_repository "pinstack-user-service/internal/infrastructure/outbound/repository/postgres"
 	"syscall"
@@ -23,31 +25,34 @@ import (
 
 func main() {
 	cfg := config.MustLoad()
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable",
-		cfg.Database.Username,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DbName)
 	ctx := context.Background()
 	log := logger.New(cfg.Env)
 
-	poolConfig, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		log.Error("Failed to parse postgres poolConfig", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
-
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	pool, err := user_repository.NewPool(ctx, user_repository.DSN("postgresql", cfg.Database), cfg.Database)
 	if err != nil {
 		log.Error("Failed to create postgres pool", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 	defer pool.Close()
 
+	replicas := make([]*pgxpool.Pool, 0, len(cfg.Database.Replicas))
+	for i, replicaDSN := range cfg.Database.Replicas {
+		replica, err := user_repository.NewPool(ctx, replicaDSN, cfg.Database)
+		if err != nil {
+			log.Error("Failed to create postgres replica pool",
+				slog.Int("replica", i),
+				slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer replica.Close()
+		replicas = append(replicas, replica)
+	}
+
 	log.Info("Connecting to Redis",
+		slog.String("mode", cfg.Redis.Mode),
 		slog.String("address", cfg.Redis.Address),
 		slog.Int("port", cfg.Redis.Port),
+		slog.Any("addresses", cfg.Redis.Addresses),
 		slog.Int("db", cfg.Redis.DB))
 	redisClient, err := redis_cache.NewClient(cfg.Redis, log)
 	if err != nil {
@@ -62,20 +67,83 @@ func main() {
 
 	metrics := prometheus_metrics.NewPrometheusMetricsProvider()
 
-	metrics.SetServiceHealth(true)
-
-	userCache := redis_cache.NewUserCache(redisClient, log, metrics)
+	metrics.SetCacheAvailable(redisClient.Available())
+	redisClient.OnAvailabilityChange(metrics.SetCacheAvailable)
+
+	redisUserCache := redis_cache.NewUserCache(redisClient, cfg.Redis, log, metrics)
+	var userCache cache.UserCache = redisUserCache
+	if cfg.LocalCache.Enabled {
+		invalidator := redis_cache.NewInvalidator(redisClient, cfg.LocalCache.InvalidationChannel, log)
+		localCache := local_cache.NewUserCache(userCache, invalidator, cfg.LocalCache, log, metrics)
+		if err := localCache.Start(ctx); err != nil {
+			log.Error("Failed to subscribe to cache invalidations", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		// Invalidations published while Redis was unreachable never arrived.
+		redisClient.OnAvailabilityChange(func(available bool) {
+			if available {
+				localCache.Purge()
+			}
+		})
+		userCache = localCache
+		log.Info("Local user cache enabled",
+			slog.Int("size", cfg.LocalCache.Size),
+			slog.Duration("ttl", cfg.LocalCache.TTL))
+	}
+	usernameIndex := redis_cache.NewUsernameIndex(redisClient, log, metrics)
+	searchCache := redis_cache.NewSearchCache(redisClient, cfg.Redis, log, metrics)
+	accessTracker := redis_cache.NewAccessTracker(redisClient, cfg.Warmup, log)
+	trackerCtx, stopTracker := context.WithCancel(ctx)
+	defer stopTracker()
+	accessTracker.Start(trackerCtx)
+
+	passwordHasher := argon2id.NewPasswordHasher(cfg.Hasher)
+
+	pageTokens := user_service.NewPageTokenCodec([]byte(cfg.Search.PageTokenSecret))
+
+	dbRouter := user_repository.NewRouter(pool, replicas, cfg.Database, log, metrics)
+	routerCtx, stopRouter := context.WithCancel(ctx)
+	defer stopRouter()
+	dbRouter.Start(routerCtx)
+	user_repository.NewPoolStatsCollector(dbRouter, cfg.Database.PoolStatsInterval, metrics).Start(routerCtx)
+	if len(replicas) > 0 {
+		log.Info("Postgres read replicas enabled",
+			slog.Int("replicas", len(replicas)),
+			slog.Duration("max_lag", cfg.Database.ReplicaMaxLag),
+			slog.Duration("read_your_writes_window", cfg.Database.ReadYourWritesWindow))
+	}
 
-	userRepo := user_repository.NewUserRepository(pool, log, metrics)
-	originalUserService := user_service.NewUserService(userRepo, log, metrics)
+	userRepo := user_repository.NewUserRepository(dbRouter, log, metrics)
+	txManager := user_repository.NewTxManager(pool)
+	indexCtx, stopIndexBuilder := context.WithCancel(ctx)
+	defer stopIndexBuilder()
+	user_service.NewUsernameIndexBuilder(userRepo, usernameIndex, cfg.Redis.UsernameIndexCheckInterval, log).Start(indexCtx)
+	originalUserService := user_service.NewUserService(userRepo, txManager, passwordHasher, pageTokens, log, metrics)
 
 	userService := user_service.NewUserServiceCacheDecorator(
 		originalUserService,
+		userRepo,
 		userCache,
+		usernameIndex,
+		searchCache,
+		accessTracker,
+		txManager,
+		cfg.Redis.SearchPopulatesCache,
 		log,
 		metrics,
 	)
 
+	// Warm-up fills Redis directly: going through the local cache would
+	// broadcast an invalidation for every user it loads.
+	if cfg.Warmup.Enabled {
+		warmer := user_service.NewCacheWarmer(originalUserService, redisUserCache, accessTracker,
+			cfg.Warmup.Users, cfg.Warmup.BatchSize, cfg.Warmup.Concurrency, log)
+		warmupCtx, cancel := context.WithTimeout(ctx, cfg.Warmup.Timeout)
+		warmer.WarmUp(warmupCtx)
+		cancel()
+	}
+	metrics.SetServiceHealth(true)
+
 	userGRPCApi := user_grpc.NewUserGRPCService(userService, log)
 	grpcServer := infra_grpc.NewServer(userGRPCApi, cfg.GRPCServer.Address, cfg.GRPCServer.Port, log, metrics)
 
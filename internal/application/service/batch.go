@@ -0,0 +1,39 @@
+package service
+
+import "pinstack-user-service/internal/domain/models"
+
+// MaxBatchSize caps how many ids a single GetUsersByIDs call may ask for.
+const MaxBatchSize = 100
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// orderUsersByIDs arranges found in the order of ids and returns the ids
+// that have no matching user.
+func orderUsersByIDs(ids []int64, found []*models.User) ([]*models.User, []int64) {
+	byID := make(map[int64]*models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	users := make([]*models.User, 0, len(ids))
+	missing := make([]int64, 0)
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return users, missing
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strconv"
+	"strings"
 
 	"pinstack-user-service/internal/domain/models"
 	input "pinstack-user-service/internal/domain/ports/input"
@@ -11,26 +13,52 @@ import (
 	"pinstack-user-service/internal/domain/ports/output/cache"
 
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"golang.org/x/sync/singleflight"
 )
 
 type UserServiceCacheDecorator struct {
-	service   input.UserService
-	userCache cache.UserCache
-	log       output.Logger
-	metrics   output.MetricsProvider
+	service       input.UserService
+	repo          output.UserRepository
+	userCache     cache.UserCache
+	usernameIndex cache.UsernameIndex
+	searchCache   cache.SearchCache
+	accessTracker cache.AccessTracker
+	txManager     output.TxManager
+	log           output.Logger
+	metrics       output.MetricsProvider
+
+	// cacheSearchResults makes Search and SearchPage cache every user they
+	// return.
+	cacheSearchResults bool
+
+	// loads coalesces concurrent cache misses for the same key into a single
+	// call to the wrapped service.
+	loads singleflight.Group
 }
 
 func NewUserServiceCacheDecorator(
 	service input.UserService,
+	repo output.UserRepository,
 	userCache cache.UserCache,
+	usernameIndex cache.UsernameIndex,
+	searchCache cache.SearchCache,
+	accessTracker cache.AccessTracker,
+	txManager output.TxManager,
+	cacheSearchResults bool,
 	log output.Logger,
 	metrics output.MetricsProvider,
 ) input.UserService {
 	return &UserServiceCacheDecorator{
-		service:   service,
-		userCache: userCache,
-		log:       log,
-		metrics:   metrics,
+		service:            service,
+		repo:               repo,
+		userCache:          userCache,
+		usernameIndex:      usernameIndex,
+		searchCache:        searchCache,
+		accessTracker:      accessTracker,
+		txManager:          txManager,
+		cacheSearchResults: cacheSearchResults,
+		log:                log,
+		metrics:            metrics,
 	}
 }
 
@@ -44,12 +72,28 @@ func (d *UserServiceCacheDecorator) Create(ctx context.Context, user *models.Use
 		return nil, err
 	}
 
+	// SetUser overwrites any not-found entries for the new id, username and
+	// email. If it fails, drop the keys so no stale tombstone hides the user.
 	if err := d.userCache.SetUser(ctx, result); err != nil {
 		d.log.Warn("Failed to cache created user",
 			slog.Int64("user_id", result.ID),
 			slog.String("error", err.Error()))
+		if err := d.userCache.DeleteUser(ctx, result); err != nil {
+			d.log.Warn("Failed to clear cache entries for created user",
+				slog.Int64("user_id", result.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	if err := d.usernameIndex.AddUsernames(ctx, result.Username); err != nil {
+		d.log.Warn("Failed to index created username",
+			slog.Int64("user_id", result.ID),
+			slog.String("username", result.Username),
+			slog.String("error", err.Error()))
 	}
 
+	d.invalidateSearchPages(ctx, result.ID)
+
 	return result, nil
 }
 
@@ -60,8 +104,14 @@ func (d *UserServiceCacheDecorator) Get(ctx context.Context, id int64) (*models.
 	if err == nil {
 		d.log.Debug("User found in cache", slog.Int64("user_id", id))
 		d.metrics.IncrementCacheHits()
+		d.accessTracker.RecordAccess(id)
 		return cachedUser, nil
 	}
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		d.log.Debug("User cached as not found", slog.Int64("user_id", id))
+		d.metrics.IncrementCacheNegativeHits()
+		return nil, custom_errors.ErrUserNotFound
+	}
 
 	if !errors.Is(err, custom_errors.ErrCacheMiss) {
 		d.log.Warn("Failed to get user from cache",
@@ -72,18 +122,70 @@ func (d *UserServiceCacheDecorator) Get(ctx context.Context, id int64) (*models.
 	}
 
 	d.log.Debug("User cache miss, fetching from service", slog.Int64("user_id", id))
-	user, err := d.service.Get(ctx, id)
-	if err != nil {
-		return nil, err
+	return d.load(ctx, "id:"+strconv.FormatInt(id, 10), "get",
+		func(ctx context.Context) (*models.User, error) {
+			return d.service.Get(ctx, id)
+		},
+		func(ctx context.Context) error {
+			return d.userCache.FillUserNotFoundByID(ctx, id)
+		})
+}
+
+func (d *UserServiceCacheDecorator) GetUsersByIDs(ctx context.Context, ids []int64) ([]*models.User, []int64, error) {
+	d.log.Debug("Getting users by IDs with cache decorator", slog.Int("count", len(ids)))
+
+	ids = uniqueIDs(ids)
+	if len(ids) > MaxBatchSize {
+		return d.service.GetUsersByIDs(ctx, ids)
+	}
+	if len(ids) == 0 {
+		return []*models.User{}, []int64{}, nil
 	}
 
-	if err := d.userCache.SetUser(ctx, user); err != nil {
-		d.log.Warn("Failed to cache user",
-			slog.Int64("user_id", id),
+	cached, err := d.userCache.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		d.log.Warn("Failed to get users from cache",
+			slog.Int("count", len(ids)),
 			slog.String("error", err.Error()))
+		cached = map[int64]*models.User{}
 	}
 
-	return user, nil
+	misses := make([]int64, 0, len(ids)-len(cached))
+	for _, id := range ids {
+		if _, ok := cached[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+
+	found := make([]*models.User, 0, len(ids))
+	for _, user := range cached {
+		found = append(found, user)
+	}
+
+	if len(misses) > 0 {
+		d.log.Debug("Users cache miss, fetching from service",
+			slog.Int("hits", len(cached)),
+			slog.Int("misses", len(misses)))
+		fetched, _, err := d.service.GetUsersByIDs(ctx, misses)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, user := range fetched {
+			if err := d.userCache.FillUser(ctx, user); err != nil {
+				d.log.Warn("Failed to cache user from batch",
+					slog.Int64("user_id", user.ID),
+					slog.String("error", err.Error()))
+			}
+		}
+		found = append(found, fetched...)
+	}
+
+	users, missing := orderUsersByIDs(ids, found)
+	for _, user := range users {
+		d.accessTracker.RecordAccess(user.ID)
+	}
+	return users, missing, nil
 }
 
 func (d *UserServiceCacheDecorator) GetByUsername(ctx context.Context, username string) (*models.User, error) {
@@ -93,8 +195,14 @@ func (d *UserServiceCacheDecorator) GetByUsername(ctx context.Context, username
 	if err == nil {
 		d.log.Debug("User found in cache by username", slog.String("username", username))
 		d.metrics.IncrementCacheHits()
+		d.accessTracker.RecordAccess(cachedUser.ID)
 		return cachedUser, nil
 	}
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		d.log.Debug("Username cached as not found", slog.String("username", username))
+		d.metrics.IncrementCacheNegativeHits()
+		return nil, custom_errors.ErrUserNotFound
+	}
 
 	if !errors.Is(err, custom_errors.ErrCacheMiss) {
 		d.log.Warn("Failed to get user by username from cache",
@@ -105,18 +213,13 @@ func (d *UserServiceCacheDecorator) GetByUsername(ctx context.Context, username
 	}
 
 	d.log.Debug("User username cache miss, fetching from service", slog.String("username", username))
-	user, err := d.service.GetByUsername(ctx, username)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := d.userCache.SetUser(ctx, user); err != nil {
-		d.log.Warn("Failed to cache user by username",
-			slog.String("username", username),
-			slog.String("error", err.Error()))
-	}
-
-	return user, nil
+	return d.load(ctx, "username:"+username, "get_by_username",
+		func(ctx context.Context) (*models.User, error) {
+			return d.service.GetByUsername(ctx, username)
+		},
+		func(ctx context.Context) error {
+			return d.userCache.FillUserNotFoundByUsername(ctx, username)
+		})
 }
 
 func (d *UserServiceCacheDecorator) GetByEmail(ctx context.Context, email string) (*models.User, error) {
@@ -126,8 +229,14 @@ func (d *UserServiceCacheDecorator) GetByEmail(ctx context.Context, email string
 	if err == nil {
 		d.log.Debug("User found in cache by email", slog.String("email", email))
 		d.metrics.IncrementCacheHits()
+		d.accessTracker.RecordAccess(cachedUser.ID)
 		return cachedUser, nil
 	}
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		d.log.Debug("Email cached as not found", slog.String("email", email))
+		d.metrics.IncrementCacheNegativeHits()
+		return nil, custom_errors.ErrUserNotFound
+	}
 
 	if !errors.Is(err, custom_errors.ErrCacheMiss) {
 		d.log.Warn("Failed to get user by email from cache",
@@ -138,58 +247,165 @@ func (d *UserServiceCacheDecorator) GetByEmail(ctx context.Context, email string
 	}
 
 	d.log.Debug("User email cache miss, fetching from service", slog.String("email", email))
-	user, err := d.service.GetByEmail(ctx, email)
-	if err != nil {
-		return nil, err
-	}
+	return d.load(ctx, "email:"+email, "get_by_email",
+		func(ctx context.Context) (*models.User, error) {
+			return d.service.GetByEmail(ctx, email)
+		},
+		func(ctx context.Context) error {
+			return d.userCache.FillUserNotFoundByEmail(ctx, email)
+		})
+}
 
-	if err := d.userCache.SetUser(ctx, user); err != nil {
-		d.log.Warn("Failed to cache user by email",
-			slog.String("email", email),
-			slog.String("error", err.Error()))
-	}
+// load runs fetch once for all concurrent callers asking for the same key and
+// caches the result, or records a not-found entry through markNotFound when
+// the user does not exist. The shared call is detached from the first caller's
+// cancellation so one client going away does not fail everyone waiting on it;
+// each caller still stops waiting when its own context is done.
+func (d *UserServiceCacheDecorator) load(
+	ctx context.Context,
+	key, operation string,
+	fetch func(ctx context.Context) (*models.User, error),
+	markNotFound func(ctx context.Context) error,
+) (*models.User, error) {
+	// Shared is also set for the caller whose function ran the load, so only
+	// the callers that joined it count as coalesced.
+	var leader bool
+	results := d.loads.DoChan(key, func() (interface{}, error) {
+		leader = true
+		loadCtx := context.WithoutCancel(ctx)
+
+		user, err := fetch(loadCtx)
+		if err != nil {
+			if errors.Is(err, custom_errors.ErrUserNotFound) {
+				if cacheErr := markNotFound(loadCtx); cacheErr != nil {
+					d.log.Warn("Failed to cache not found user",
+						slog.String("key", key),
+						slog.String("error", cacheErr.Error()))
+				}
+			}
+			return nil, err
+		}
 
-	return user, nil
+		if err := d.userCache.FillUser(loadCtx, user); err != nil {
+			d.log.Warn("Failed to cache user",
+				slog.String("key", key),
+				slog.String("error", err.Error()))
+		}
+		return user, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-results:
+		if res.Shared && !leader {
+			d.metrics.IncrementCacheCoalescedRequests(operation)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// Every caller gets its own copy of the shared result.
+		user := *res.Val.(*models.User)
+		d.accessTracker.RecordAccess(user.ID)
+		return &user, nil
+	}
 }
 
-func (d *UserServiceCacheDecorator) Update(ctx context.Context, user *models.User) (*models.User, error) {
+func (d *UserServiceCacheDecorator) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
 	d.log.Debug("Updating user with cache decorator",
 		slog.Int64("user_id", user.ID),
 		slog.String("username", user.Username))
 
-	oldUser, err := d.service.Get(ctx, user.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	updatedUser, err := d.service.Update(ctx, user)
+	// The old row stays locked until the update commits, so the keys it
+	// owned are still the ones to invalidate below.
+	var oldUser, updatedUser *models.User
+	err := d.withinTx(ctx, "update", func(ctx context.Context) error {
+		var err error
+		oldUser, err = d.getForUpdate(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		updatedUser, err = d.service.Update(ctx, user, fields)
+		return err
+	})
 	if err != nil {
+		// The client may have taken its version from a stale entry; drop it
+		// so the read it retries with sees the current row.
+		if errors.Is(err, models.ErrVersionConflict) {
+			if err := d.userCache.DeleteUser(ctx, oldUser); err != nil {
+				d.log.Warn("Failed to clear cache entries after version conflict",
+					slog.Int64("user_id", oldUser.ID),
+					slog.String("error", err.Error()))
+			}
+		}
 		return nil, err
 	}
 
-	if err := d.userCache.DeleteUser(ctx, oldUser); err != nil {
-		d.log.Warn("Failed to invalidate old user cache after update",
-			slog.Int64("user_id", oldUser.ID),
-			slog.String("old_username", oldUser.Username),
-			slog.String("old_email", oldUser.Email),
-			slog.String("error", err.Error()))
-	}
-
 	if err := d.userCache.SetUser(ctx, updatedUser); err != nil {
 		d.log.Warn("Failed to cache updated user",
 			slog.Int64("user_id", updatedUser.ID),
 			slog.String("error", err.Error()))
+		if err := d.userCache.DeleteUser(ctx, updatedUser); err != nil {
+			d.log.Warn("Failed to clear cache entries for updated user",
+				slog.Int64("user_id", updatedUser.ID),
+				slog.String("error", err.Error()))
+		}
 	}
 
+	// Pointers the user no longer owns are released. A read that loaded the
+	// old row cannot put them back: its write to the id key is older than the
+	// one above, so its pointers are never written.
+	if oldUser.Username != updatedUser.Username {
+		if err := d.userCache.ReleaseUsername(ctx, oldUser.Username); err != nil {
+			d.log.Warn("Failed to invalidate old username cache after update",
+				slog.Int64("user_id", oldUser.ID),
+				slog.String("old_username", oldUser.Username),
+				slog.String("error", err.Error()))
+		}
+	}
+	if oldUser.Email != updatedUser.Email {
+		if err := d.userCache.ReleaseEmail(ctx, oldUser.Email); err != nil {
+			d.log.Warn("Failed to invalidate old email cache after update",
+				slog.Int64("user_id", oldUser.ID),
+				slog.String("old_email", oldUser.Email),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	if oldUser.Username != updatedUser.Username {
+		if err := d.usernameIndex.RemoveUsername(ctx, oldUser.Username); err != nil {
+			d.log.Warn("Failed to remove old username from index after update",
+				slog.Int64("user_id", oldUser.ID),
+				slog.String("old_username", oldUser.Username),
+				slog.String("error", err.Error()))
+		}
+		if err := d.usernameIndex.AddUsernames(ctx, updatedUser.Username); err != nil {
+			d.log.Warn("Failed to index updated username",
+				slog.Int64("user_id", updatedUser.ID),
+				slog.String("username", updatedUser.Username),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	d.invalidateSearchPages(ctx, updatedUser.ID)
+
 	return updatedUser, nil
 }
 
 func (d *UserServiceCacheDecorator) Delete(ctx context.Context, id int64) error {
 	d.log.Debug("Deleting user with cache decorator", slog.Int64("user_id", id))
 
-	user, err := d.service.Get(ctx, id)
+	var user *models.User
+	err := d.withinTx(ctx, "delete", func(ctx context.Context) error {
+		var err error
+		user, err = d.getForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		return d.service.Delete(ctx, id)
+	})
 	if err != nil {
-		if errors.Is(err, custom_errors.ErrUserNotFound) {
+		if user == nil && errors.Is(err, custom_errors.ErrUserNotFound) {
 			if cacheErr := d.userCache.DeleteUserByID(ctx, id); cacheErr != nil {
 				d.log.Warn("Failed to invalidate user cache by ID after deletion attempt",
 					slog.Int64("user_id", id),
@@ -199,41 +415,185 @@ func (d *UserServiceCacheDecorator) Delete(ctx context.Context, id int64) error
 		return err
 	}
 
-	err = d.service.Delete(ctx, id)
-	if err != nil {
-		return err
+	d.markDeleted(ctx, user)
+
+	if err := d.usernameIndex.RemoveUsername(ctx, user.Username); err != nil {
+		d.log.Warn("Failed to remove username from index after deletion",
+			slog.Int64("user_id", id),
+			slog.String("username", user.Username),
+			slog.String("error", err.Error()))
 	}
 
-	if err := d.userCache.DeleteUser(ctx, user); err != nil {
-		d.log.Warn("Failed to invalidate user cache after deletion",
+	d.invalidateSearchPages(ctx, id)
+
+	return nil
+}
+
+// getForUpdate reads the user from the repository and, inside a transaction,
+// locks it until the transaction ends. A locking read must see the row
+// itself, so it never goes through the cache.
+func (d *UserServiceCacheDecorator) getForUpdate(ctx context.Context, id int64) (*models.User, error) {
+	user, err := d.repo.GetCredentialsByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrUserNotFound) {
+			return nil, custom_errors.ErrUserNotFound
+		}
+		d.log.Error("Failed to get user by id for update",
 			slog.Int64("user_id", id),
 			slog.String("error", err.Error()))
+		return nil, custom_errors.ErrDatabaseQuery
 	}
+	user.Password = ""
+	return user, nil
+}
 
+// withinTx runs fn in a transaction. Errors from fn are returned as they are;
+// failing to begin or commit the transaction is reported as ErrDatabaseQuery.
+func (d *UserServiceCacheDecorator) withinTx(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	var fnErr error
+	err := d.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		fnErr = fn(ctx)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		d.log.Error("Transaction failed",
+			slog.String("operation", operation),
+			slog.String("error", err.Error()))
+		return custom_errors.ErrDatabaseQuery
+	}
 	return nil
 }
 
-func (d *UserServiceCacheDecorator) Search(ctx context.Context, query string, page, limit int) ([]*models.User, int, error) {
+func (d *UserServiceCacheDecorator) Search(ctx context.Context, query string, offset, limit int) ([]*models.User, int, error) {
 	d.log.Debug("Searching users with cache decorator",
 		slog.String("query", query),
-		slog.Int("page", page),
+		slog.Int("offset", offset),
 		slog.Int("limit", limit))
 
-	users, count, err := d.service.Search(ctx, query, page, limit)
+	// Matching is case-insensitive, so differently cased queries share a page.
+	key := cache.SearchKey{Query: strings.ToLower(query), Offset: offset, Limit: limit}
+	generation, page := d.getSearchPage(ctx, key)
+	if page != nil {
+		return page.Users, page.Total, nil
+	}
+
+	users, count, err := d.service.Search(searchContext(ctx, generation), query, offset, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	d.cacheSearchHits(ctx, users)
+	d.setSearchPage(ctx, generation, key, &cache.SearchPage{Users: users, Total: count})
+
+	return users, count, nil
+}
+
+func (d *UserServiceCacheDecorator) SearchPage(ctx context.Context, query, pageToken string, limit int) ([]*models.User, int, string, error) {
+	d.log.Debug("Searching users by page token with cache decorator",
+		slog.String("query", query),
+		slog.Int("limit", limit))
+
+	// Page tokens are bound to the exact query, so it is not normalized here.
+	key := cache.SearchKey{Query: query, PageToken: pageToken, Limit: limit}
+	generation, page := d.getSearchPage(ctx, key)
+	if page != nil {
+		return page.Users, page.Total, page.NextPageToken, nil
+	}
+
+	users, count, nextPageToken, err := d.service.SearchPage(searchContext(ctx, generation), query, pageToken, limit)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	d.cacheSearchHits(ctx, users)
+	d.setSearchPage(ctx, generation, key, &cache.SearchPage{Users: users, Total: count, NextPageToken: nextPageToken})
+
+	return users, count, nextPageToken, nil
+}
+
+// getSearchPage returns the cached page for key, if any, along with the
+// generation a freshly loaded page has to be stored under. The generation is
+// read before the service is queried, so a page loaded concurrently with a
+// write is stored under the generation that write retires. Zero means the
+// page must not be stored.
+func (d *UserServiceCacheDecorator) getSearchPage(ctx context.Context, key cache.SearchKey) (int64, *cache.SearchPage) {
+	generation, err := d.searchCache.Generation(ctx)
+	if err != nil {
+		if !errors.Is(err, custom_errors.ErrCacheMiss) {
+			d.log.Warn("Failed to get search generation",
+				slog.String("query", key.Query),
+				slog.String("error", err.Error()))
+		}
+		return 0, nil
+	}
+
+	page, err := d.searchCache.GetPage(ctx, generation, key)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrCacheMiss) {
+			d.metrics.IncrementCacheMisses()
+		} else {
+			d.log.Warn("Failed to get search page from cache",
+				slog.String("query", key.Query),
+				slog.String("error", err.Error()))
+		}
+		return generation, nil
+	}
+
+	d.log.Debug("Search page found in cache",
+		slog.String("query", key.Query),
+		slog.Int("count", len(page.Users)))
+	d.metrics.IncrementCacheHits()
+	return generation, page
+}
+
+// searchContext returns the context to run a search with whose page will be
+// stored under generation. Such a search must read from the primary: a page
+// read from a replica that has not replayed the write that started the
+// generation would be served to every instance until it expires.
+func searchContext(ctx context.Context, generation int64) context.Context {
+	if generation == 0 {
+		return ctx
+	}
+	return output.WithPrimaryReads(ctx)
+}
+
+func (d *UserServiceCacheDecorator) setSearchPage(ctx context.Context, generation int64, key cache.SearchKey, page *cache.SearchPage) {
+	if generation == 0 {
+		return
+	}
+	if err := d.searchCache.SetPage(ctx, generation, key, page); err != nil {
+		d.log.Warn("Failed to cache search page",
+			slog.String("query", key.Query),
+			slog.String("error", err.Error()))
+	}
+}
+
+// invalidateSearchPages retires every cached search page after a write that
+// can change search results. If it fails, pages stay stale until they expire.
+func (d *UserServiceCacheDecorator) invalidateSearchPages(ctx context.Context, userID int64) {
+	if err := d.searchCache.BumpGeneration(ctx); err != nil {
+		d.log.Warn("Failed to invalidate cached search pages",
+			slog.Int64("user_id", userID),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (d *UserServiceCacheDecorator) cacheSearchHits(ctx context.Context, users []*models.User) {
+	if !d.cacheSearchResults {
+		return
+	}
+
 	for _, user := range users {
-		if err := d.userCache.SetUser(ctx, user); err != nil {
+		if err := d.userCache.FillUser(ctx, user); err != nil {
 			d.log.Warn("Failed to cache user from search results",
 				slog.Int64("user_id", user.ID),
 				slog.String("username", user.Username),
 				slog.String("error", err.Error()))
 		}
 	}
-
-	return users, count, nil
 }
 
 func (d *UserServiceCacheDecorator) UpdatePassword(ctx context.Context, id int64, oldPassword, newPassword string) error {
@@ -244,11 +604,7 @@ func (d *UserServiceCacheDecorator) UpdatePassword(ctx context.Context, id int64
 		return err
 	}
 
-	if err := d.userCache.DeleteUserByID(ctx, id); err != nil {
-		d.log.Warn("Failed to invalidate user cache after password update",
-			slog.Int64("user_id", id),
-			slog.String("error", err.Error()))
-	}
+	d.refresh(ctx, id)
 
 	return nil
 }
@@ -263,11 +619,89 @@ func (d *UserServiceCacheDecorator) UpdateAvatar(ctx context.Context, id int64,
 		return err
 	}
 
+	d.refresh(ctx, id)
+	d.invalidateSearchPages(ctx, id)
+
+	return nil
+}
+
+// markDeleted replaces the id entry of a deleted user with a not-found entry
+// one version past the locked row, which outranks any copy of the row that a
+// concurrent read may still be about to cache, and releases its pointers.
+func (d *UserServiceCacheDecorator) markDeleted(ctx context.Context, user *models.User) {
+	if err := d.userCache.SetUserNotFoundByID(ctx, user.ID, user.Version+1); err != nil {
+		d.log.Warn("Failed to invalidate user cache by ID after deletion",
+			slog.Int64("user_id", user.ID),
+			slog.String("error", err.Error()))
+	}
+	if err := d.userCache.ReleaseUsername(ctx, user.Username); err != nil {
+		d.log.Warn("Failed to invalidate user cache by username after deletion",
+			slog.Int64("user_id", user.ID),
+			slog.String("username", user.Username),
+			slog.String("error", err.Error()))
+	}
+	if err := d.userCache.ReleaseEmail(ctx, user.Email); err != nil {
+		d.log.Warn("Failed to invalidate user cache by email after deletion",
+			slog.Int64("user_id", user.ID),
+			slog.String("email", user.Email),
+			slog.String("error", err.Error()))
+	}
+}
+
+// refresh re-reads a user after a write that does not return it and caches
+// the new version. Writing through rather than deleting keeps versioning in
+// charge: a delete would let an older in-flight read repopulate the key.
+func (d *UserServiceCacheDecorator) refresh(ctx context.Context, id int64) {
+	user, err := d.service.Get(ctx, id)
+	if err == nil {
+		err = d.userCache.SetUser(ctx, user)
+	}
+	if err == nil {
+		return
+	}
+
+	d.log.Warn("Failed to refresh user cache after write, invalidating",
+		slog.Int64("user_id", id),
+		slog.String("error", err.Error()))
 	if err := d.userCache.DeleteUserByID(ctx, id); err != nil {
-		d.log.Warn("Failed to invalidate user cache after avatar update",
+		d.log.Warn("Failed to invalidate user cache",
 			slog.Int64("user_id", id),
 			slog.String("error", err.Error()))
 	}
+}
 
-	return nil
+func (d *UserServiceCacheDecorator) VerifyCredentials(ctx context.Context, login, password string) (*models.User, error) {
+	d.log.Debug("Verifying credentials with cache decorator", slog.String("login", login))
+
+	return d.service.VerifyCredentials(ctx, login, password)
+}
+
+// AutocompleteUsernames serves from the Redis username index once a build
+// has marked it complete; from then on its answer is final, including a short
+// or empty page. Until then prefixes go to the service.
+func (d *UserServiceCacheDecorator) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	d.log.Debug("Autocompleting usernames with cache decorator",
+		slog.String("prefix", prefix),
+		slog.Int("limit", limit))
+
+	if prefix == "" {
+		return d.service.AutocompleteUsernames(ctx, prefix, limit)
+	}
+
+	usernames, err := d.usernameIndex.AutocompleteUsernames(ctx, prefix, limit)
+	switch {
+	case err == nil:
+		d.log.Debug("Usernames found in index", slog.String("prefix", prefix))
+		d.metrics.IncrementCacheHits()
+		return usernames, nil
+	case errors.Is(err, custom_errors.ErrCacheMiss):
+		d.metrics.IncrementCacheMisses()
+	default:
+		d.log.Warn("Failed to autocomplete usernames from index",
+			slog.String("prefix", prefix),
+			slog.String("error", err.Error()))
+	}
+
+	d.log.Debug("Username index miss, fetching from service", slog.String("prefix", prefix))
+	return d.service.AutocompleteUsernames(ctx, prefix, limit)
 }
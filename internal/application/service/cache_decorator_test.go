@@ -0,0 +1,694 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+
+	"pinstack-user-service/internal/domain/models"
+	user_service "pinstack-user-service/internal/domain/ports/input"
+	output "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/domain/ports/output/cache"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+	"pinstack-user-service/internal/infrastructure/outbound/repository/memory"
+	"pinstack-user-service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUserCache is a map-backed cache.UserCache with the same key layout,
+// not-found entries, pointers and version checks as the Redis adapter. It
+// counts single-key lookups and writes, which unlike fills would invalidate
+// other instances' local caches.
+type fakeUserCache struct {
+	mu      sync.Mutex
+	entries map[string]fakeCacheEntry
+	lookups atomic.Int32
+	writes  atomic.Int32
+}
+
+// fakeCacheEntry is a user entry under an id key, or a pointer holding only
+// the user id under an email or username key.
+type fakeCacheEntry struct {
+	user     models.User
+	notFound bool
+	version  int64
+}
+
+func newFakeUserCache() *fakeUserCache {
+	return &fakeUserCache{entries: make(map[string]fakeCacheEntry)}
+}
+
+func fakeIDKey(id int64) string { return "id:" + strconv.FormatInt(id, 10) }
+
+func (c *fakeUserCache) get(key string) (*models.User, error) {
+	c.lookups.Add(1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	switch {
+	case !ok:
+		return nil, custom_errors.ErrCacheMiss
+	case entry.notFound:
+		return nil, custom_errors.ErrUserNotFound
+	}
+	user := entry.user
+	return &user, nil
+}
+
+// getByPointer resolves a pointer through the id entry it names, which must
+// still match the lookup.
+func (c *fakeUserCache) getByPointer(key string, matches func(*models.User) bool) (*models.User, error) {
+	c.lookups.Add(1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pointer, ok := c.entries[key]
+	switch {
+	case !ok:
+		return nil, custom_errors.ErrCacheMiss
+	case pointer.notFound:
+		return nil, custom_errors.ErrUserNotFound
+	}
+	entry, ok := c.entries[fakeIDKey(pointer.user.ID)]
+	if !ok || entry.notFound || !matches(&entry.user) {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	user := entry.user
+	return &user, nil
+}
+
+// set mirrors the Lua script: a key keeps its value if that is newer.
+func (c *fakeUserCache) set(key string, entry fakeCacheEntry) bool {
+	if current, ok := c.entries[key]; ok && current.version > entry.version {
+		return false
+	}
+	c.entries[key] = entry
+	return true
+}
+
+func (c *fakeUserCache) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	return c.get(fakeIDKey(userID))
+}
+
+func (c *fakeUserCache) GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	users := make(map[int64]*models.User)
+	for _, id := range userIDs {
+		if entry, ok := c.entries[fakeIDKey(id)]; ok && !entry.notFound {
+			user := entry.user
+			users[id] = &user
+		}
+	}
+	return users, nil
+}
+
+func (c *fakeUserCache) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return c.getByPointer("email:"+email, func(user *models.User) bool { return user.Email == email })
+}
+
+func (c *fakeUserCache) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return c.getByPointer("username:"+username, func(user *models.User) bool { return user.Username == username })
+}
+
+func (c *fakeUserCache) SetUser(ctx context.Context, user *models.User) error {
+	c.writes.Add(1)
+	return c.FillUser(ctx, user)
+}
+
+// FillUser writes the pointers, unconditionally, only once the id entry took
+// the write.
+func (c *fakeUserCache) FillUser(ctx context.Context, user *models.User) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.set(fakeIDKey(user.ID), fakeCacheEntry{user: *user, version: user.Version}) {
+		return nil
+	}
+	pointer := fakeCacheEntry{user: models.User{ID: user.ID}, version: user.Version}
+	c.entries["username:"+user.Username] = pointer
+	c.entries["email:"+user.Email] = pointer
+	return nil
+}
+
+func (c *fakeUserCache) SetUserNotFoundByID(ctx context.Context, userID int64, version int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(fakeIDKey(userID), fakeCacheEntry{notFound: true, version: version})
+	return nil
+}
+
+func (c *fakeUserCache) release(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *fakeUserCache) ReleaseUsername(ctx context.Context, username string) error {
+	return c.release("username:" + username)
+}
+
+func (c *fakeUserCache) ReleaseEmail(ctx context.Context, email string) error {
+	return c.release("email:" + email)
+}
+
+// fillNotFound mirrors SET NX: a read's tombstone only fills an empty key, at
+// version zero.
+func (c *fakeUserCache) fillNotFound(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = fakeCacheEntry{notFound: true}
+	}
+	return nil
+}
+
+func (c *fakeUserCache) FillUserNotFoundByID(ctx context.Context, userID int64) error {
+	return c.fillNotFound(fakeIDKey(userID))
+}
+
+func (c *fakeUserCache) FillUserNotFoundByUsername(ctx context.Context, username string) error {
+	return c.fillNotFound("username:" + username)
+}
+
+func (c *fakeUserCache) FillUserNotFoundByEmail(ctx context.Context, email string) error {
+	return c.fillNotFound("email:" + email)
+}
+
+func (c *fakeUserCache) DeleteUser(ctx context.Context, user *models.User) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, fakeIDKey(user.ID))
+	delete(c.entries, "username:"+user.Username)
+	delete(c.entries, "email:"+user.Email)
+	return nil
+}
+
+func (c *fakeUserCache) DeleteUserByID(ctx context.Context, userID int64) error {
+	c.mu.Lock()
+	entry, ok := c.entries[fakeIDKey(userID)]
+	c.mu.Unlock()
+	if !ok || entry.notFound {
+		c.mu.Lock()
+		delete(c.entries, fakeIDKey(userID))
+		c.mu.Unlock()
+		return nil
+	}
+	return c.DeleteUser(ctx, &entry.user)
+}
+
+type noopUsernameIndex struct{}
+
+func (noopUsernameIndex) AddUsernames(ctx context.Context, usernames ...string) error { return nil }
+
+func (noopUsernameIndex) RemoveUsername(ctx context.Context, username string) error { return nil }
+
+func (noopUsernameIndex) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	return nil, custom_errors.ErrCacheMiss
+}
+
+func (noopUsernameIndex) BeginBuild(ctx context.Context) (bool, error) { return false, nil }
+
+func (noopUsernameIndex) MarkComplete(ctx context.Context) error { return nil }
+
+type noopAccessTracker struct{}
+
+func (noopAccessTracker) RecordAccess(userIDs ...int64) {}
+
+func (noopAccessTracker) MostAccessed(ctx context.Context, limit int) ([]int64, error) {
+	return nil, nil
+}
+
+// recordingTxManager runs fn directly, noting whether a transaction is open
+// and failing the commit when commitErr is set.
+type recordingTxManager struct {
+	inTx      atomic.Bool
+	commitErr error
+}
+
+func (m *recordingTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.inTx.Store(true)
+	defer m.inTx.Store(false)
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return m.commitErr
+}
+
+// fakeSearchCache is a map-backed cache.SearchCache.
+type fakeSearchCache struct {
+	mu         sync.Mutex
+	generation int64
+	pages      map[int64]map[cache.SearchKey]cache.SearchPage
+}
+
+func newFakeSearchCache() *fakeSearchCache {
+	return &fakeSearchCache{generation: 1, pages: make(map[int64]map[cache.SearchKey]cache.SearchPage)}
+}
+
+func (c *fakeSearchCache) Generation(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation, nil
+}
+
+func (c *fakeSearchCache) BumpGeneration(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	return nil
+}
+
+func (c *fakeSearchCache) GetPage(ctx context.Context, generation int64, key cache.SearchKey) (*cache.SearchPage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, ok := c.pages[generation][key]
+	if !ok {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	return &page, nil
+}
+
+func (c *fakeSearchCache) SetPage(ctx context.Context, generation int64, key cache.SearchKey, page *cache.SearchPage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pages[generation] == nil {
+		c.pages[generation] = make(map[cache.SearchKey]cache.SearchPage)
+	}
+	c.pages[generation][key] = *page
+	return nil
+}
+
+func setupDecoratorTest(t *testing.T) (user_service.UserService, *mocks.UserService, *mocks.UserRepository, *fakeUserCache) {
+	mockService := mocks.NewUserService(t)
+	mockRepo := mocks.NewUserRepository(t)
+	userCache := newFakeUserCache()
+	log := logger.New("test")
+	metrics := prometheus.NewPrometheusMetricsProvider()
+	decorator := NewUserServiceCacheDecorator(mockService, mockRepo, userCache, noopUsernameIndex{}, newFakeSearchCache(), noopAccessTracker{}, memory.NewTxManager(), true, log, metrics)
+	return decorator, mockService, mockRepo, userCache
+}
+
+func TestUserServiceCacheDecorator_GetCoalescesMisses(t *testing.T) {
+	mockService := mocks.NewUserService(t)
+	userCache := newFakeUserCache()
+	metrics := &coalescedMetrics{MetricsProvider: prometheus.NewPrometheusMetricsProvider()}
+	decorator := NewUserServiceCacheDecorator(mockService, mocks.NewUserRepository(t), userCache, noopUsernameIndex{}, newFakeSearchCache(), noopAccessTracker{}, memory.NewTxManager(), true, logger.New("test"), metrics)
+
+	const callers = 10
+	release := make(chan struct{})
+	mockService.On("Get", mock.Anything, int64(1)).
+		Run(func(args mock.Arguments) { <-release }).
+		Return(&models.User{ID: 1, Username: "popular"}, nil).
+		Once()
+
+	var (
+		wg      sync.WaitGroup
+		waiting atomic.Int32
+	)
+	results := make([]*models.User, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := &waitingContext{Context: context.Background(), waiting: &waiting}
+			results[i], errs[i] = decorator.Get(ctx, 1)
+		}(i)
+	}
+
+	// Every caller has joined the load once it waits for the result, and the
+	// load cannot finish before release.
+	require.Eventually(t, func() bool {
+		return waiting.Load() == callers
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "popular", results[i].Username)
+	}
+	for i := 1; i < callers; i++ {
+		assert.NotSame(t, results[0], results[i])
+	}
+	assert.Equal(t, int32(callers-1), metrics.coalesced.Load(), "the caller running the load is not coalesced")
+
+	cached, err := userCache.GetUserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "popular", cached.Username)
+}
+
+// waitingContext counts calls to Done, which the decorator makes only when a
+// caller starts waiting for a shared load.
+type waitingContext struct {
+	context.Context
+	waiting *atomic.Int32
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.waiting.Add(1)
+	return c.Context.Done()
+}
+
+// coalescedMetrics counts coalesced requests and passes every other metric
+// through.
+type coalescedMetrics struct {
+	output.MetricsProvider
+	coalesced atomic.Int32
+}
+
+func (m *coalescedMetrics) IncrementCacheCoalescedRequests(operation string) {
+	m.coalesced.Add(1)
+}
+
+func TestUserServiceCacheDecorator_GetCallerCancellation(t *testing.T) {
+	decorator, mockService, _, userCache := setupDecoratorTest(t)
+
+	release := make(chan struct{})
+	mockService.On("Get", mock.Anything, int64(1)).
+		Run(func(args mock.Arguments) { <-release }).
+		Return(&models.User{ID: 1, Username: "slow"}, nil).
+		Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := decorator.Get(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The abandoned load still completes and fills the cache.
+	close(release)
+	require.Eventually(t, func() bool {
+		_, err := userCache.GetUserByID(context.Background(), 1)
+		return err == nil
+	}, time.Second, time.Millisecond)
+	assert.Zero(t, userCache.writes.Load(), "a read fills the cache without invalidating")
+}
+
+func TestUserServiceCacheDecorator_NegativeCaching(t *testing.T) {
+	decorator, mockService, _, _ := setupDecoratorTest(t)
+	ctx := context.Background()
+
+	mockService.On("GetByUsername", mock.Anything, "newbie").Return(nil, custom_errors.ErrUserNotFound).Once()
+
+	_, err := decorator.GetByUsername(ctx, "newbie")
+	assert.Equal(t, custom_errors.ErrUserNotFound, err)
+
+	// Served from the not-found entry without asking the service again.
+	_, err = decorator.GetByUsername(ctx, "newbie")
+	assert.Equal(t, custom_errors.ErrUserNotFound, err)
+
+	created := &models.User{ID: 7, Username: "newbie", Email: "newbie@example.com", Version: 1}
+	mockService.On("Create", mock.Anything, mock.Anything).Return(created, nil).Once()
+	_, err = decorator.Create(ctx, &models.User{Username: "newbie", Email: "newbie@example.com"})
+	require.NoError(t, err)
+
+	got, err := decorator.GetByUsername(ctx, "newbie")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.ID)
+}
+
+func TestUserServiceCacheDecorator_StaleNotFoundAfterCreate(t *testing.T) {
+	decorator, mockService, _, _ := setupDecoratorTest(t)
+	ctx := context.Background()
+
+	// The read ran on a replica that had not seen the row yet and only
+	// finishes after the create cached the user.
+	created := &models.User{ID: 7, Username: "newbie", Email: "newbie@example.com", Version: 1}
+	mockService.On("Create", mock.Anything, mock.Anything).Return(created, nil).Once()
+	mockService.On("GetByUsername", mock.Anything, "newbie").
+		Run(func(args mock.Arguments) {
+			_, err := decorator.Create(ctx, &models.User{Username: "newbie", Email: "newbie@example.com"})
+			require.NoError(t, err)
+		}).
+		Return(nil, custom_errors.ErrUserNotFound).Once()
+
+	_, err := decorator.GetByUsername(ctx, "newbie")
+	assert.Equal(t, custom_errors.ErrUserNotFound, err)
+
+	got, err := decorator.GetByUsername(ctx, "newbie")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), got.ID)
+}
+
+func TestUserServiceCacheDecorator_UpdateClearsNegativeEntry(t *testing.T) {
+	decorator, mockService, mockRepo, userCache := setupDecoratorTest(t)
+	ctx := context.Background()
+
+	require.NoError(t, userCache.FillUserNotFoundByEmail(ctx, "new@example.com"))
+
+	oldUser := &models.User{ID: 1, Username: "user", Email: "old@example.com"}
+	updated := &models.User{ID: 1, Username: "user", Email: "new@example.com", UpdatedAt: time.Now(), Version: 2}
+	mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Return(oldUser, nil).Once()
+	fields := models.UserFields{models.UserFieldEmail}
+	mockService.On("Update", mock.Anything, updated, fields).Return(updated, nil).Once()
+
+	_, err := decorator.Update(ctx, updated, fields)
+	require.NoError(t, err)
+
+	got, err := decorator.GetByEmail(ctx, "new@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.ID)
+}
+
+// pausingRepository holds the next GetByID after it has read the row, which
+// lets a test run a write between a cache-miss read and its cache fill.
+type pausingRepository struct {
+	*memory.Repository
+	armed  atomic.Bool
+	loaded chan struct{}
+	resume chan struct{}
+}
+
+func (r *pausingRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	user, err := r.Repository.GetByID(ctx, id)
+	if r.armed.CompareAndSwap(true, false) {
+		close(r.loaded)
+		<-r.resume
+	}
+	return user, err
+}
+
+func TestUserServiceCacheDecorator_StaleReadAfterUpdate(t *testing.T) {
+	log := logger.New("test")
+	metrics := prometheus.NewPrometheusMetricsProvider()
+	repo := &pausingRepository{
+		Repository: memory.NewUserRepository(log),
+		loaded:     make(chan struct{}),
+		resume:     make(chan struct{}),
+	}
+	userCache := newFakeUserCache()
+	decorator := NewUserServiceCacheDecorator(
+		NewUserService(repo, memory.NewTxManager(), testHasher, testPageTokens, log, metrics), repo,
+		userCache, noopUsernameIndex{}, newFakeSearchCache(), noopAccessTracker{}, memory.NewTxManager(), true, log, metrics)
+	ctx := context.Background()
+
+	created, err := decorator.Create(ctx, &models.User{
+		Username: "before",
+		Email:    "user@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, userCache.DeleteUser(ctx, created))
+
+	// A read misses the cache and loads the row, then stalls before caching it.
+	repo.armed.Store(true)
+	staleRead := make(chan error, 1)
+	go func() {
+		_, err := decorator.Get(ctx, created.ID)
+		staleRead <- err
+	}()
+	<-repo.loaded
+
+	// Meanwhile the user is renamed and the new version is cached.
+	update := *created
+	update.Username = "after"
+	updated, err := decorator.Update(ctx, &update, models.UserFields{models.UserFieldUsername})
+	require.NoError(t, err)
+
+	// The stalled read now tries to cache the row it loaded before the update.
+	close(repo.resume)
+	require.NoError(t, <-staleRead)
+
+	got, err := userCache.GetUserByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "after", got.Username)
+	assert.Equal(t, updated.Version, got.Version)
+
+	_, err = userCache.GetUserByUsername(ctx, "before")
+	assert.Equal(t, custom_errors.ErrCacheMiss, err)
+
+	got, err = userCache.GetUserByEmail(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "after", got.Username)
+}
+
+func TestUserServiceCacheDecorator_StaleReadAfterDelete(t *testing.T) {
+	log := logger.New("test")
+	metrics := prometheus.NewPrometheusMetricsProvider()
+	repo := &pausingRepository{
+		Repository: memory.NewUserRepository(log),
+		loaded:     make(chan struct{}),
+		resume:     make(chan struct{}),
+	}
+	userCache := newFakeUserCache()
+	decorator := NewUserServiceCacheDecorator(
+		NewUserService(repo, memory.NewTxManager(), testHasher, testPageTokens, log, metrics), repo,
+		userCache, noopUsernameIndex{}, newFakeSearchCache(), noopAccessTracker{}, memory.NewTxManager(), true, log, metrics)
+	ctx := context.Background()
+
+	created, err := decorator.Create(ctx, &models.User{
+		Username: "doomed",
+		Email:    "doomed@example.com",
+		Password: "password123",
+	})
+	require.NoError(t, err)
+	require.NoError(t, userCache.DeleteUser(ctx, created))
+
+	repo.armed.Store(true)
+	staleRead := make(chan error, 1)
+	go func() {
+		_, err := decorator.Get(ctx, created.ID)
+		staleRead <- err
+	}()
+	<-repo.loaded
+
+	require.NoError(t, decorator.Delete(ctx, created.ID))
+
+	close(repo.resume)
+	require.NoError(t, <-staleRead)
+
+	_, err = userCache.GetUserByID(ctx, created.ID)
+	assert.Equal(t, custom_errors.ErrUserNotFound, err)
+	_, err = userCache.GetUserByUsername(ctx, "doomed")
+	assert.Equal(t, custom_errors.ErrCacheMiss, err)
+}
+
+func TestUserServiceCacheDecorator_SearchPopulatesCache(t *testing.T) {
+	for _, cacheSearchResults := range []bool{true, false} {
+		t.Run(strconv.FormatBool(cacheSearchResults), func(t *testing.T) {
+			mockService := mocks.NewUserService(t)
+			userCache := newFakeUserCache()
+			decorator := NewUserServiceCacheDecorator(mockService, mocks.NewUserRepository(t), userCache, noopUsernameIndex{}, newFakeSearchCache(),
+				noopAccessTracker{}, memory.NewTxManager(), cacheSearchResults, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+			ctx := context.Background()
+
+			mockService.On("Search", mock.Anything, "ali", 0, 10).
+				Return([]*models.User{{ID: 1, Username: "alice"}}, 1, nil).
+				Once()
+
+			users, total, err := decorator.Search(ctx, "ali", 0, 10)
+			require.NoError(t, err)
+			assert.Len(t, users, 1)
+			assert.Equal(t, 1, total)
+
+			_, err = userCache.GetUserByID(ctx, 1)
+			if cacheSearchResults {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+			}
+			assert.Zero(t, userCache.writes.Load())
+		})
+	}
+}
+
+// primaryReads matches contexts that ask for primary reads: searches whose
+// page gets cached must not be served by a lagging replica.
+var primaryReads = mock.MatchedBy(func(ctx context.Context) bool { return output.PrimaryReads(ctx) })
+
+func TestUserServiceCacheDecorator_SearchPagesInvalidatedByWrites(t *testing.T) {
+	decorator, mockService, _, _ := setupDecoratorTest(t)
+	ctx := context.Background()
+
+	alice := &models.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	mockService.On("Search", primaryReads, "", 0, 20).
+		Return([]*models.User{alice}, 1, nil).
+		Once()
+
+	for i := 0; i < 2; i++ {
+		users, total, err := decorator.Search(ctx, "", 0, 20)
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, 1, total)
+	}
+
+	bob := &models.User{ID: 2, Username: "bob", Email: "bob@example.com"}
+	mockService.On("Create", mock.Anything, mock.Anything).Return(bob, nil).Once()
+	_, err := decorator.Create(ctx, &models.User{Username: "bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+
+	mockService.On("Search", primaryReads, "", 0, 20).
+		Return([]*models.User{alice, bob}, 2, nil).
+		Once()
+
+	users, total, err := decorator.Search(ctx, "", 0, 20)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, 2, total)
+}
+
+func TestUserServiceCacheDecorator_WritesRunInTransaction(t *testing.T) {
+	mockService := mocks.NewUserService(t)
+	mockRepo := mocks.NewUserRepository(t)
+	userCache := newFakeUserCache()
+	txManager := &recordingTxManager{}
+	decorator := NewUserServiceCacheDecorator(mockService, mockRepo, userCache, noopUsernameIndex{}, newFakeSearchCache(),
+		noopAccessTracker{}, txManager, true, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+	ctx := context.Background()
+
+	user := &models.User{ID: 1, Username: "user", Email: "user@example.com", UpdatedAt: time.Now()}
+	inTx := func(mock.Arguments) { assert.True(t, txManager.inTx.Load()) }
+	fields := models.UserFields{models.UserFieldBio}
+	mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Run(inTx).Return(user, nil)
+	mockService.On("Update", mock.Anything, user, fields).Run(inTx).Return(user, nil).Once()
+	mockService.On("Delete", mock.Anything, int64(1)).Run(inTx).Return(nil).Once()
+
+	_, err := decorator.Update(ctx, user, fields)
+	require.NoError(t, err)
+	require.NoError(t, decorator.Delete(ctx, 1))
+
+	t.Run("commit failure", func(t *testing.T) {
+		txManager.commitErr = assert.AnError
+		mockService.On("Update", mock.Anything, user, fields).Return(user, nil).Once()
+
+		_, err := decorator.Update(ctx, user, fields)
+		assert.Equal(t, custom_errors.ErrDatabaseQuery, err)
+	})
+}
+
+func TestUserServiceCacheDecorator_AutocompleteFromCompleteIndex(t *testing.T) {
+	ctx := context.Background()
+	mockService := mocks.NewUserService(t)
+	index := newFakeUsernameIndex()
+	decorator := NewUserServiceCacheDecorator(mockService, mocks.NewUserRepository(t), newFakeUserCache(), index, newFakeSearchCache(),
+		noopAccessTracker{}, memory.NewTxManager(), true, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+
+	mockService.On("AutocompleteUsernames", ctx, "al", 5).Return([]string{"alice"}, nil).Once()
+	usernames, err := decorator.AutocompleteUsernames(ctx, "al", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, usernames, "an incomplete index falls back to the service")
+
+	_, err = index.BeginBuild(ctx)
+	require.NoError(t, err)
+	require.NoError(t, index.AddUsernames(ctx, "alice"))
+	require.NoError(t, index.MarkComplete(ctx))
+
+	// The service mock has no more expectations: a short or empty page from
+	// a complete index is final.
+	usernames, err = decorator.AutocompleteUsernames(ctx, "al", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, usernames)
+
+	usernames, err = decorator.AutocompleteUsernames(ctx, "zz", 5)
+	require.NoError(t, err)
+	assert.Empty(t, usernames)
+}
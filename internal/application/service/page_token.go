@@ -0,0 +1,75 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"pinstack-user-service/internal/domain/models"
+)
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+type pageTokenPayload struct {
+	Cursor models.SearchCursor `json:"c"`
+	Query  string              `json:"q"`
+}
+
+// PageTokenCodec turns search cursors into opaque, HMAC-signed page tokens.
+// The token is bound to the query it was issued for, so it cannot be
+// replayed against a different search or tampered with by clients.
+type PageTokenCodec struct {
+	secret []byte
+}
+
+func NewPageTokenCodec(secret []byte) *PageTokenCodec {
+	return &PageTokenCodec{secret: secret}
+}
+
+func (c *PageTokenCodec) Encode(query string, cursor models.SearchCursor) (string, error) {
+	payload, err := json.Marshal(pageTokenPayload{Cursor: cursor, Query: query})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+func (c *PageTokenCodec) Decode(query, token string) (*models.SearchCursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidPageToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, errInvalidPageToken
+	}
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return nil, errInvalidPageToken
+	}
+
+	var decoded pageTokenPayload
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, errInvalidPageToken
+	}
+	if decoded.Query != query {
+		return nil, errInvalidPageToken
+	}
+
+	return &decoded.Cursor, nil
+}
+
+func (c *PageTokenCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
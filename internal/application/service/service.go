@@ -7,19 +7,42 @@ import (
 	"pinstack-user-service/internal/domain/models"
 	input "pinstack-user-service/internal/domain/ports/input"
 	output "pinstack-user-service/internal/domain/ports/output"
+	"strings"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 )
 
 type Service struct {
-	repo    output.UserRepository
-	log     output.Logger
-	metrics output.MetricsProvider
+	repo       output.UserRepository
+	txManager  output.TxManager
+	hasher     output.PasswordHasher
+	pageTokens *PageTokenCodec
+	log        output.Logger
+	metrics    output.MetricsProvider
+	dummyHash  func() (string, error)
 }
 
-func NewUserService(repo output.UserRepository, log output.Logger, metrics output.MetricsProvider) input.UserService {
-	return &Service{repo: repo, log: log, metrics: metrics}
+func NewUserService(
+	repo output.UserRepository,
+	txManager output.TxManager,
+	hasher output.PasswordHasher,
+	pageTokens *PageTokenCodec,
+	log output.Logger,
+	metrics output.MetricsProvider,
+) input.UserService {
+	return &Service{
+		repo:       repo,
+		txManager:  txManager,
+		hasher:     hasher,
+		pageTokens: pageTokens,
+		log:        log,
+		metrics:    metrics,
+		dummyHash: sync.OnceValues(func() (string, error) {
+			return hasher.Hash("pinstack-dummy-password")
+		}),
+	}
 }
 
 func (s *Service) Create(ctx context.Context, user *models.User) (*models.User, error) {
@@ -27,7 +50,19 @@ func (s *Service) Create(ctx context.Context, user *models.User) (*models.User,
 		slog.String("username", user.Username),
 		slog.String("email", user.Email))
 
-	createdUser, err := s.repo.Create(ctx, user)
+	passwordHash, err := s.hasher.Hash(user.Password)
+	if err != nil {
+		s.metrics.IncrementUserOperations("create", false)
+		s.log.Error("Failed to hash password",
+			slog.String("error", err.Error()),
+			slog.String("username", user.Username))
+		return nil, custom_errors.ErrUserCreateFailed
+	}
+
+	toCreate := *user
+	toCreate.Password = passwordHash
+
+	createdUser, err := s.repo.Create(ctx, &toCreate)
 	if err != nil {
 		s.metrics.IncrementUserOperations("create", false)
 		switch {
@@ -80,6 +115,42 @@ func (s *Service) Get(ctx context.Context, id int64) (*models.User, error) {
 	return user, nil
 }
 
+// GetUsersByIDs returns the users found for ids in the order the ids were
+// given, without duplicates, together with the ids that do not exist.
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []int64) ([]*models.User, []int64, error) {
+	s.log.Debug("Getting users by IDs", slog.Int("count", len(ids)))
+
+	ids = uniqueIDs(ids)
+	if len(ids) > MaxBatchSize {
+		s.metrics.IncrementUserOperations("get_batch", false)
+		s.log.Debug("Too many ids in batch request",
+			slog.Int("count", len(ids)),
+			slog.Int("max", MaxBatchSize))
+		return nil, nil, custom_errors.ErrInvalidInput
+	}
+	if len(ids) == 0 {
+		s.metrics.IncrementUserOperations("get_batch", true)
+		return []*models.User{}, []int64{}, nil
+	}
+
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		s.metrics.IncrementUserOperations("get_batch", false)
+		s.log.Error("Failed to get users by ids",
+			slog.String("error", err.Error()),
+			slog.Int("count", len(ids)))
+		return nil, nil, custom_errors.ErrDatabaseQuery
+	}
+
+	users, missing := orderUsersByIDs(ids, found)
+
+	s.metrics.IncrementUserOperations("get_batch", true)
+	s.log.Debug("Users retrieved successfully",
+		slog.Int("found", len(users)),
+		slog.Int("missing", len(missing)))
+	return users, missing, nil
+}
+
 func (s *Service) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	s.log.Debug("Getting user by username", slog.String("username", username))
 
@@ -130,12 +201,13 @@ func (s *Service) GetByEmail(ctx context.Context, email string) (*models.User, e
 	return user, nil
 }
 
-func (s *Service) Update(ctx context.Context, user *models.User) (*models.User, error) {
+func (s *Service) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
 	s.log.Debug("Updating user",
 		slog.Int64("id", user.ID),
-		slog.String("username", user.Username))
+		slog.String("username", user.Username),
+		slog.Any("fields", fields))
 
-	updatedUser, err := s.repo.Update(ctx, user)
+	updatedUser, err := s.repo.Update(ctx, user, fields)
 	if err != nil {
 		s.metrics.IncrementUserOperations("update", false)
 		switch {
@@ -148,6 +220,11 @@ func (s *Service) Update(ctx context.Context, user *models.User) (*models.User,
 		case errors.Is(err, custom_errors.ErrUserNotFound):
 			s.log.Debug("User not found", slog.Int64("id", user.ID))
 			return nil, custom_errors.ErrUserNotFound
+		case errors.Is(err, models.ErrVersionConflict):
+			s.log.Debug("User version conflict",
+				slog.Int64("id", user.ID),
+				slog.Int64("version", user.Version))
+			return nil, models.ErrVersionConflict
 		default:
 			s.log.Error("Failed update user",
 				slog.String("error", err.Error()),
@@ -186,13 +263,12 @@ func (s *Service) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *Service) Search(ctx context.Context, query string, page, limit int) ([]*models.User, int, error) {
+func (s *Service) Search(ctx context.Context, query string, offset, limit int) ([]*models.User, int, error) {
 	s.log.Debug("Searching users",
 		slog.String("query", query),
-		slog.Int("page", page),
+		slog.Int("offset", offset),
 		slog.Int("limit", limit))
 
-	offset := (page - 1) * limit
 	users, count, err := s.repo.Search(ctx, query, offset, limit)
 	if err != nil {
 		s.metrics.IncrementUserOperations("search", false)
@@ -200,14 +276,14 @@ func (s *Service) Search(ctx context.Context, query string, page, limit int) ([]
 		case errors.Is(err, pgx.ErrNoRows):
 			s.log.Debug("No users found for search query",
 				slog.String("query", query),
-				slog.Int("page", page),
+				slog.Int("offset", offset),
 				slog.Int("limit", limit))
 			return []*models.User{}, 0, nil
 		default:
 			s.log.Error("Failed to search users",
 				slog.String("error", err.Error()),
 				slog.String("query", query),
-				slog.Int("page", page),
+				slog.Int("offset", offset),
 				slog.Int("limit", limit))
 			return nil, 0, custom_errors.ErrDatabaseQuery
 		}
@@ -219,10 +295,117 @@ func (s *Service) Search(ctx context.Context, query string, page, limit int) ([]
 	return users, count, nil
 }
 
+func (s *Service) SearchPage(ctx context.Context, query, pageToken string, limit int) ([]*models.User, int, string, error) {
+	s.log.Debug("Searching users by page token",
+		slog.String("query", query),
+		slog.Bool("has_page_token", pageToken != ""),
+		slog.Int("limit", limit))
+
+	var cursor *models.SearchCursor
+	if pageToken != "" {
+		decoded, err := s.pageTokens.Decode(query, pageToken)
+		if err != nil {
+			s.metrics.IncrementUserOperations("search", false)
+			s.log.Debug("Invalid search page token",
+				slog.String("query", query),
+				slog.String("error", err.Error()))
+			return nil, 0, "", custom_errors.ErrInvalidInput
+		}
+		cursor = decoded
+	}
+
+	// One extra row tells whether another page exists without a second query.
+	hits, count, err := s.repo.SearchAfter(ctx, query, cursor, limit+1)
+	if err != nil {
+		s.metrics.IncrementUserOperations("search", false)
+		s.log.Error("Failed to search users by page token",
+			slog.String("error", err.Error()),
+			slog.String("query", query),
+			slog.Int("limit", limit))
+		return nil, 0, "", custom_errors.ErrDatabaseQuery
+	}
+
+	nextPageToken := ""
+	if len(hits) > limit {
+		hits = hits[:limit]
+		nextPageToken, err = s.pageTokens.Encode(query, hits[len(hits)-1].Cursor)
+		if err != nil {
+			s.metrics.IncrementUserOperations("search", false)
+			s.log.Error("Failed to encode search page token",
+				slog.String("error", err.Error()),
+				slog.String("query", query))
+			return nil, 0, "", custom_errors.ErrSearchFailed
+		}
+	}
+
+	users := make([]*models.User, 0, len(hits))
+	for _, hit := range hits {
+		users = append(users, hit.User)
+	}
+
+	s.metrics.IncrementUserOperations("search", true)
+	s.log.Debug("Page search completed successfully",
+		slog.String("query", query),
+		slog.Int("count", count),
+		slog.Bool("has_next_page", nextPageToken != ""))
+	return users, count, nextPageToken, nil
+}
+
+func (s *Service) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	s.log.Debug("Autocompleting usernames",
+		slog.String("prefix", prefix),
+		slog.Int("limit", limit))
+
+	if prefix == "" {
+		s.metrics.IncrementUserOperations("autocomplete", true)
+		return []string{}, nil
+	}
+
+	usernames, err := s.repo.AutocompleteUsernames(ctx, prefix, limit)
+	if err != nil {
+		s.metrics.IncrementUserOperations("autocomplete", false)
+		s.log.Error("Failed to autocomplete usernames",
+			slog.String("error", err.Error()),
+			slog.String("prefix", prefix),
+			slog.Int("limit", limit))
+		return nil, custom_errors.ErrDatabaseQuery
+	}
+
+	s.metrics.IncrementUserOperations("autocomplete", true)
+	s.log.Debug("Usernames autocompleted successfully",
+		slog.String("prefix", prefix),
+		slog.Int("count", len(usernames)))
+	return usernames, nil
+}
+
+// UpdatePassword checks the old password and stores the new one in one
+// transaction, with the user locked so a concurrent change cannot slip in
+// between.
 func (s *Service) UpdatePassword(ctx context.Context, id int64, oldPassword, newPassword string) error {
 	s.log.Debug("Updating user password", slog.Int64("id", id))
 
-	_, err := s.repo.GetByID(ctx, id)
+	var opErr error
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		opErr = s.updatePassword(ctx, id, oldPassword, newPassword)
+		return opErr
+	})
+	if opErr != nil {
+		return opErr
+	}
+	if err != nil {
+		s.metrics.IncrementUserOperations("update_password", false)
+		s.log.Error("Password update transaction failed",
+			slog.String("error", err.Error()),
+			slog.Int64("id", id))
+		return custom_errors.ErrDatabaseQuery
+	}
+	s.metrics.IncrementUserOperations("update_password", true)
+	s.log.Debug("User password updated successfully", slog.Int64("id", id))
+	return nil
+}
+
+func (s *Service) updatePassword(ctx context.Context, id int64, oldPassword, newPassword string) error {
+	user, err := s.repo.GetCredentialsByIDForUpdate(ctx, id)
 	if err != nil {
 		s.metrics.IncrementUserOperations("update_password", false)
 		switch {
@@ -237,7 +420,30 @@ func (s *Service) UpdatePassword(ctx context.Context, id int64, oldPassword, new
 		}
 	}
 
-	err = s.repo.UpdatePassword(ctx, id, newPassword)
+	ok, err := s.hasher.Verify(oldPassword, user.Password)
+	if err != nil {
+		s.metrics.IncrementUserOperations("update_password", false)
+		s.log.Error("Failed to verify old password",
+			slog.String("error", err.Error()),
+			slog.Int64("id", id))
+		return custom_errors.ErrInvalidPassword
+	}
+	if !ok {
+		s.metrics.IncrementUserOperations("update_password", false)
+		s.log.Debug("Old password does not match", slog.Int64("id", id))
+		return custom_errors.ErrInvalidPassword
+	}
+
+	newPasswordHash, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		s.metrics.IncrementUserOperations("update_password", false)
+		s.log.Error("Failed to hash new password",
+			slog.String("error", err.Error()),
+			slog.Int64("id", id))
+		return custom_errors.ErrPasswordUpdateFailed
+	}
+
+	err = s.repo.UpdatePassword(ctx, id, newPasswordHash)
 	if err != nil {
 		s.metrics.IncrementUserOperations("update_password", false)
 		s.log.Error("Failed update user",
@@ -245,8 +451,6 @@ func (s *Service) UpdatePassword(ctx context.Context, id int64, oldPassword, new
 			slog.Int64("id", id))
 		return custom_errors.ErrDatabaseQuery
 	}
-	s.metrics.IncrementUserOperations("update_password", true)
-	s.log.Debug("User password updated successfully", slog.Int64("id", id))
 	return nil
 }
 
@@ -275,3 +479,76 @@ func (s *Service) UpdateAvatar(ctx context.Context, id int64, avatarURL string)
 	s.log.Debug("User avatar updated successfully", slog.Int64("id", id))
 	return nil
 }
+
+func (s *Service) VerifyCredentials(ctx context.Context, login, password string) (*models.User, error) {
+	s.log.Debug("Verifying user credentials", slog.String("login", login))
+
+	var (
+		user *models.User
+		err  error
+	)
+	if strings.Contains(login, "@") {
+		user, err = s.repo.GetCredentialsByEmail(ctx, login)
+	} else {
+		user, err = s.repo.GetCredentialsByUsername(ctx, login)
+	}
+	if err != nil && !errors.Is(err, custom_errors.ErrUserNotFound) {
+		s.metrics.IncrementUserOperations("verify_credentials", false)
+		s.log.Error("Failed to get user for credentials check",
+			slog.String("error", err.Error()),
+			slog.String("login", login))
+		return nil, custom_errors.ErrDatabaseQuery
+	}
+
+	if user == nil {
+		// Burn the same hashing work as a real check so response time
+		// does not reveal whether the account exists.
+		if dummy, dummyErr := s.dummyHash(); dummyErr == nil {
+			_, _ = s.hasher.Verify(password, dummy)
+		}
+		s.metrics.IncrementUserOperations("verify_credentials", false)
+		s.log.Debug("User not found for credentials check", slog.String("login", login))
+		return nil, custom_errors.ErrInvalidCredentials
+	}
+
+	ok, err := s.hasher.Verify(password, user.Password)
+	if err != nil || !ok {
+		s.metrics.IncrementUserOperations("verify_credentials", false)
+		if err != nil {
+			s.log.Error("Failed to verify password",
+				slog.String("error", err.Error()),
+				slog.Int64("id", user.ID))
+		} else {
+			s.log.Debug("Password does not match", slog.Int64("id", user.ID))
+		}
+		return nil, custom_errors.ErrInvalidCredentials
+	}
+
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user.ID, password)
+	}
+
+	s.metrics.IncrementUserOperations("verify_credentials", true)
+	s.log.Debug("Credentials verified successfully", slog.Int64("id", user.ID))
+
+	verified := *user
+	verified.Password = ""
+	return &verified, nil
+}
+
+func (s *Service) rehashPassword(ctx context.Context, id int64, password string) {
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		s.log.Warn("Failed to rehash password",
+			slog.String("error", err.Error()),
+			slog.Int64("id", id))
+		return
+	}
+	if err := s.repo.UpdatePassword(ctx, id, newHash); err != nil {
+		s.log.Warn("Failed to store rehashed password",
+			slog.String("error", err.Error()),
+			slog.Int64("id", id))
+		return
+	}
+	s.log.Debug("Password rehashed with current parameters", slog.Int64("id", id))
+}
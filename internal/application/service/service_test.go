@@ -2,28 +2,58 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 
 	"pinstack-user-service/internal/domain/models"
 	user_service "pinstack-user-service/internal/domain/ports/input"
+	"pinstack-user-service/internal/infrastructure/config"
 	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/hasher/argon2id"
 	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+	"pinstack-user-service/internal/infrastructure/outbound/repository/memory"
 	"pinstack-user-service/mocks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
+var testHasher = argon2id.NewPasswordHasher(config.Hasher{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+})
+
+var testPageTokens = NewPageTokenCodec([]byte("test-secret"))
+
 func setupTest(t *testing.T) (user_service.UserService, *mocks.UserRepository, func()) {
 	mockRepo := mocks.NewUserRepository(t)
 	log := logger.New("test")
 	metrics := prometheus.NewPrometheusMetricsProvider()
-	service := NewUserService(mockRepo, log, metrics)
+	service := NewUserService(mockRepo, memory.NewTxManager(), testHasher, testPageTokens, log, metrics)
 	return service, mockRepo, func() {}
 }
 
+func mustHash(t *testing.T, password string) string {
+	hash, err := testHasher.Hash(password)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	return hash
+}
+
+func matchesPassword(password string) interface{} {
+	return mock.MatchedBy(func(hash string) bool {
+		ok, err := testHasher.Verify(password, hash)
+		return err == nil && ok
+	})
+}
+
 func TestUserService_Create(t *testing.T) {
 	service, mockRepo, cleanup := setupTest(t)
 	defer cleanup()
@@ -43,7 +73,10 @@ func TestUserService_Create(t *testing.T) {
 				Password: "password123",
 			},
 			mockSetup: func() {
-				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.User")).Return(
+				mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+					ok, err := testHasher.Verify("password123", u.Password)
+					return err == nil && ok && u.Password != "password123"
+				})).Return(
 					&models.User{
 						ID:       1,
 						Username: "testuser",
@@ -167,6 +200,93 @@ func TestUserService_Get(t *testing.T) {
 	}
 }
 
+func TestUserService_GetUsersByIDs(t *testing.T) {
+	service, mockRepo, cleanup := setupTest(t)
+	defer cleanup()
+
+	tests := []struct {
+		name        string
+		ids         []int64
+		mockSetup   func()
+		wantIDs     []int64
+		wantMissing []int64
+		wantErr     error
+	}{
+		{
+			name: "preserves input order and reports missing ids",
+			ids:  []int64{3, 1, 999, 3},
+			mockSetup: func() {
+				mockRepo.On("GetByIDs", mock.Anything, []int64{3, 1, 999}).Return(
+					[]*models.User{
+						{ID: 1, Username: "first"},
+						{ID: 3, Username: "third"},
+					}, nil).Once()
+			},
+			wantIDs:     []int64{3, 1},
+			wantMissing: []int64{999},
+		},
+		{
+			name:        "empty ids",
+			ids:         nil,
+			mockSetup:   func() {},
+			wantIDs:     []int64{},
+			wantMissing: []int64{},
+		},
+		{
+			name:      "too many ids",
+			ids:       sequentialIDs(MaxBatchSize + 1),
+			mockSetup: func() {},
+			wantErr:   custom_errors.ErrInvalidInput,
+		},
+		{
+			name: "duplicates do not count against the limit",
+			ids:  append(sequentialIDs(MaxBatchSize), sequentialIDs(MaxBatchSize)...),
+			mockSetup: func() {
+				mockRepo.On("GetByIDs", mock.Anything, sequentialIDs(MaxBatchSize)).Return([]*models.User{}, nil).Once()
+			},
+			wantIDs:     []int64{},
+			wantMissing: sequentialIDs(MaxBatchSize),
+		},
+		{
+			name: "database error",
+			ids:  []int64{1},
+			mockSetup: func() {
+				mockRepo.On("GetByIDs", mock.Anything, []int64{1}).Return(nil, errors.New("db error")).Once()
+			},
+			wantErr: custom_errors.ErrDatabaseQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			got, missing, err := service.GetUsersByIDs(context.Background(), tt.ids)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, got)
+				assert.Nil(t, missing)
+			} else {
+				assert.NoError(t, err)
+				gotIDs := make([]int64, 0, len(got))
+				for _, user := range got {
+					gotIDs = append(gotIDs, user.ID)
+				}
+				assert.Equal(t, tt.wantIDs, gotIDs)
+				assert.Equal(t, tt.wantMissing, missing)
+			}
+		})
+	}
+}
+
+func sequentialIDs(n int) []int64 {
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	return ids
+}
+
 func TestUserService_GetByUsername(t *testing.T) {
 	service, mockRepo, cleanup := setupTest(t)
 	defer cleanup()
@@ -306,7 +426,7 @@ func TestUserService_Update(t *testing.T) {
 				Email:    "updated@example.com",
 			},
 			mockSetup: func() {
-				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(
+				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User"), mock.Anything).Return(
 					&models.User{
 						ID:       1,
 						Username: "updateduser",
@@ -328,7 +448,7 @@ func TestUserService_Update(t *testing.T) {
 				Email:    "nonexistent@example.com",
 			},
 			mockSetup: func() {
-				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(
+				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User"), mock.Anything).Return(
 					&models.User{
 						ID:       999,
 						Username: "nonexistent",
@@ -346,7 +466,7 @@ func TestUserService_Update(t *testing.T) {
 				Email:    "test@example.com",
 			},
 			mockSetup: func() {
-				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(
+				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User"), mock.Anything).Return(
 					&models.User{
 						ID:       1,
 						Username: "testuser",
@@ -356,12 +476,27 @@ func TestUserService_Update(t *testing.T) {
 			want:    nil,
 			wantErr: custom_errors.ErrDatabaseQuery,
 		},
+		{
+			name: "version conflict",
+			user: &models.User{
+				ID:       1,
+				Username: "testuser",
+				Email:    "test@example.com",
+				Version:  3,
+			},
+			mockSetup: func() {
+				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User"), mock.Anything).Return(
+					nil, models.ErrVersionConflict).Once()
+			},
+			want:    nil,
+			wantErr: models.ErrVersionConflict,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			got, err := service.Update(context.Background(), tt.user)
+			got, err := service.Update(context.Background(), tt.user, models.UpdatableUserFields)
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -428,7 +563,7 @@ func TestUserService_Search(t *testing.T) {
 	tests := []struct {
 		name      string
 		query     string
-		page      int
+		offset    int
 		limit     int
 		mockSetup func()
 		wantUsers []*models.User
@@ -436,10 +571,10 @@ func TestUserService_Search(t *testing.T) {
 		wantErr   error
 	}{
 		{
-			name:  "successful search",
-			query: "test",
-			page:  1,
-			limit: 10,
+			name:   "successful search",
+			query:  "test",
+			offset: 0,
+			limit:  10,
 			mockSetup: func() {
 				mockRepo.On("Search", mock.Anything, "test", 0, 10).Return(
 					[]*models.User{
@@ -471,10 +606,10 @@ func TestUserService_Search(t *testing.T) {
 			wantErr:   nil,
 		},
 		{
-			name:  "no results",
-			query: "nonexistent",
-			page:  1,
-			limit: 10,
+			name:   "no results",
+			query:  "nonexistent",
+			offset: 0,
+			limit:  10,
 			mockSetup: func() {
 				mockRepo.On("Search", mock.Anything, "nonexistent", 0, 10).Return(
 					[]*models.User{}, 0, nil).Once()
@@ -488,7 +623,7 @@ func TestUserService_Search(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
-			gotUsers, gotCount, err := service.Search(context.Background(), tt.query, tt.page, tt.limit)
+			gotUsers, gotCount, err := service.Search(context.Background(), tt.query, tt.offset, tt.limit)
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -510,6 +645,106 @@ func TestUserService_Search(t *testing.T) {
 	}
 }
 
+func TestUserService_SearchPage(t *testing.T) {
+	service, mockRepo, cleanup := setupTest(t)
+	defer cleanup()
+
+	page := []*models.SearchHit{
+		{User: &models.User{ID: 1, Username: "testuser1"}, Cursor: models.SearchCursor{Username: "testuser1", ID: 1, Total: 5}},
+		{User: &models.User{ID: 2, Username: "testuser2"}, Cursor: models.SearchCursor{Username: "testuser2", ID: 2, Total: 5}},
+		{User: &models.User{ID: 3, Username: "testuser3"}, Cursor: models.SearchCursor{Username: "testuser3", ID: 3, Total: 5}},
+	}
+
+	mockRepo.On("SearchAfter", mock.Anything, "test", (*models.SearchCursor)(nil), 3).Return(page, 5, nil).Once()
+
+	users, total, nextPageToken, err := service.SearchPage(context.Background(), "test", "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Len(t, users, 2)
+	assert.NotEmpty(t, nextPageToken)
+
+	mockRepo.On("SearchAfter", mock.Anything, "test", &models.SearchCursor{Username: "testuser2", ID: 2, Total: 5}, 3).Return(page[2:], 5, nil).Once()
+
+	users, total, nextPageToken, err = service.SearchPage(context.Background(), "test", nextPageToken, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Len(t, users, 1)
+	assert.Empty(t, nextPageToken)
+
+	t.Run("token for another query", func(t *testing.T) {
+		token, err := testPageTokens.Encode("other", models.SearchCursor{Username: "a", ID: 1})
+		assert.NoError(t, err)
+
+		_, _, _, err = service.SearchPage(context.Background(), "test", token, 2)
+		assert.Equal(t, custom_errors.ErrInvalidInput, err)
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		token, err := NewPageTokenCodec([]byte("other-secret")).Encode("test", models.SearchCursor{Username: "a", ID: 1})
+		assert.NoError(t, err)
+
+		_, _, _, err = service.SearchPage(context.Background(), "test", token, 2)
+		assert.Equal(t, custom_errors.ErrInvalidInput, err)
+	})
+}
+
+func TestUserService_AutocompleteUsernames(t *testing.T) {
+	service, mockRepo, cleanup := setupTest(t)
+	defer cleanup()
+
+	tests := []struct {
+		name      string
+		prefix    string
+		limit     int
+		mockSetup func()
+		want      []string
+		wantErr   error
+	}{
+		{
+			name:   "successful autocomplete",
+			prefix: "ali",
+			limit:  5,
+			mockSetup: func() {
+				mockRepo.On("AutocompleteUsernames", mock.Anything, "ali", 5).Return(
+					[]string{"alice", "Alison"}, nil).Once()
+			},
+			want: []string{"alice", "Alison"},
+		},
+		{
+			name:      "empty prefix",
+			prefix:    "",
+			limit:     5,
+			mockSetup: func() {},
+			want:      []string{},
+		},
+		{
+			name:   "database error",
+			prefix: "bo",
+			limit:  5,
+			mockSetup: func() {
+				mockRepo.On("AutocompleteUsernames", mock.Anything, "bo", 5).Return(
+					nil, errors.New("db error")).Once()
+			},
+			wantErr: custom_errors.ErrDatabaseQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			got, err := service.AutocompleteUsernames(context.Background(), tt.prefix, tt.limit)
+
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
 func TestUserService_UpdatePassword(t *testing.T) {
 	service, mockRepo, cleanup := setupTest(t)
 	defer cleanup()
@@ -528,12 +763,12 @@ func TestUserService_UpdatePassword(t *testing.T) {
 			oldPassword: "oldpass",
 			newPassword: "newpass",
 			mockSetup: func() {
-				mockRepo.On("GetByID", mock.Anything, int64(1)).Return(
+				mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Return(
 					&models.User{
 						ID:       1,
-						Password: "oldpass",
+						Password: mustHash(t, "oldpass"),
 					}, nil).Once()
-				mockRepo.On("UpdatePassword", mock.Anything, int64(1), "newpass").Return(nil).Once()
+				mockRepo.On("UpdatePassword", mock.Anything, int64(1), matchesPassword("newpass")).Return(nil).Once()
 			},
 			expectedError: nil,
 		},
@@ -543,7 +778,7 @@ func TestUserService_UpdatePassword(t *testing.T) {
 			oldPassword: "oldpass",
 			newPassword: "newpass",
 			mockSetup: func() {
-				mockRepo.On("GetByID", mock.Anything, int64(999)).Return(nil, custom_errors.ErrUserNotFound).Once()
+				mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(999)).Return(nil, custom_errors.ErrUserNotFound).Once()
 			},
 			expectedError: custom_errors.ErrUserNotFound,
 		},
@@ -553,7 +788,7 @@ func TestUserService_UpdatePassword(t *testing.T) {
 			oldPassword: "oldpass",
 			newPassword: "newpass",
 			mockSetup: func() {
-				mockRepo.On("GetByID", mock.Anything, int64(1)).Return(nil, assert.AnError).Once()
+				mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Return(nil, assert.AnError).Once()
 			},
 			expectedError: custom_errors.ErrDatabaseQuery,
 		},
@@ -563,15 +798,29 @@ func TestUserService_UpdatePassword(t *testing.T) {
 			oldPassword: "oldpass",
 			newPassword: "newpass",
 			mockSetup: func() {
-				mockRepo.On("GetByID", mock.Anything, int64(1)).Return(
+				mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Return(
 					&models.User{
 						ID:       1,
-						Password: "oldpass",
+						Password: mustHash(t, "oldpass"),
 					}, nil).Once()
-				mockRepo.On("UpdatePassword", mock.Anything, int64(1), "newpass").Return(assert.AnError).Once()
+				mockRepo.On("UpdatePassword", mock.Anything, int64(1), matchesPassword("newpass")).Return(assert.AnError).Once()
 			},
 			expectedError: custom_errors.ErrDatabaseQuery,
 		},
+		{
+			name:        "wrong old password",
+			id:          1,
+			oldPassword: "wrongpass",
+			newPassword: "newpass",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByIDForUpdate", mock.Anything, int64(1)).Return(
+					&models.User{
+						ID:       1,
+						Password: mustHash(t, "oldpass"),
+					}, nil).Once()
+			},
+			expectedError: custom_errors.ErrInvalidPassword,
+		},
 	}
 
 	for _, tt := range tests {
@@ -634,3 +883,143 @@ func TestUserService_UpdateAvatar(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_VerifyCredentials(t *testing.T) {
+	service, mockRepo, cleanup := setupTest(t)
+	defer cleanup()
+
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		login     string
+		password  string
+		mockSetup func()
+		wantID    int64
+		wantErr   error
+	}{
+		{
+			name:     "valid username and password",
+			login:    "testuser",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "testuser").Return(
+					&models.User{
+						ID:       1,
+						Username: "testuser",
+						Password: mustHash(t, "password123"),
+					}, nil).Once()
+			},
+			wantID: 1,
+		},
+		{
+			name:     "valid email and password",
+			login:    "test@example.com",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByEmail", mock.Anything, "test@example.com").Return(
+					&models.User{
+						ID:       1,
+						Email:    "test@example.com",
+						Password: mustHash(t, "password123"),
+					}, nil).Once()
+			},
+			wantID: 1,
+		},
+		{
+			name:     "wrong password",
+			login:    "testuser",
+			password: "wrongpass",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "testuser").Return(
+					&models.User{
+						ID:       1,
+						Username: "testuser",
+						Password: mustHash(t, "password123"),
+					}, nil).Once()
+			},
+			wantErr: custom_errors.ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown user",
+			login:    "ghost",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "ghost").Return(nil, custom_errors.ErrUserNotFound).Once()
+			},
+			wantErr: custom_errors.ErrInvalidCredentials,
+		},
+		{
+			name:     "database error",
+			login:    "testuser",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "testuser").Return(nil, assert.AnError).Once()
+			},
+			wantErr: custom_errors.ErrDatabaseQuery,
+		},
+		{
+			name:     "legacy hash is upgraded",
+			login:    "legacy",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "legacy").Return(
+					&models.User{
+						ID:       2,
+						Username: "legacy",
+						Password: string(legacyHash),
+					}, nil).Once()
+				mockRepo.On("UpdatePassword", mock.Anything, int64(2), matchesPassword("password123")).Return(nil).Once()
+			},
+			wantID: 2,
+		},
+		{
+			name:     "plaintext password is hashed",
+			login:    "plain",
+			password: "password123",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "plain").Return(
+					&models.User{
+						ID:       3,
+						Username: "plain",
+						Password: "password123",
+					}, nil).Once()
+				mockRepo.On("UpdatePassword", mock.Anything, int64(3), matchesPassword("password123")).Return(nil).Once()
+			},
+			wantID: 3,
+		},
+		{
+			name:     "wrong plaintext password",
+			login:    "plain",
+			password: "password1234",
+			mockSetup: func() {
+				mockRepo.On("GetCredentialsByUsername", mock.Anything, "plain").Return(
+					&models.User{
+						ID:       3,
+						Username: "plain",
+						Password: "password123",
+					}, nil).Once()
+			},
+			wantErr: custom_errors.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			got, err := service.VerifyCredentials(context.Background(), tt.login, tt.password)
+
+			if tt.wantErr != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+				assert.Equal(t, tt.wantID, got.ID)
+				assert.Empty(t, got.Password)
+			}
+		})
+	}
+}
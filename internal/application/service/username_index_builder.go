@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	output "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/domain/ports/output/cache"
+)
+
+// usernameIndexBatchSize is how many usernames are read from the repository
+// and added to the index at a time.
+const usernameIndexBatchSize = 1000
+
+// UsernameIndexBuilder copies every username from the repository into the
+// username index and marks it complete, after which autocomplete is served
+// from the index alone. It checks the index at start and then every interval,
+// so an index the cache lost, e.g. after an outage or eviction, is rebuilt.
+//
+// A username deleted while the build copies its batch can be added back after
+// the delete removed it; it stays in the index until the index is rebuilt.
+type UsernameIndexBuilder struct {
+	repo     output.UserRepository
+	index    cache.UsernameIndex
+	interval time.Duration
+	log      output.Logger
+}
+
+func NewUsernameIndexBuilder(repo output.UserRepository, index cache.UsernameIndex, interval time.Duration, log output.Logger) *UsernameIndexBuilder {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &UsernameIndexBuilder{
+		repo:     repo,
+		index:    index,
+		interval: interval,
+		log:      log,
+	}
+}
+
+// Start builds the index in the background if it is not complete, then
+// checks it again every interval until ctx is done.
+func (b *UsernameIndexBuilder) Start(ctx context.Context) {
+	go func() {
+		b.Build(ctx)
+
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.Build(ctx)
+			}
+		}
+	}()
+}
+
+// Build fills the index from the repository unless it is already complete.
+// Failures are logged; the next check tries again.
+func (b *UsernameIndexBuilder) Build(ctx context.Context) {
+	begun, err := b.index.BeginBuild(ctx)
+	if err != nil {
+		b.log.Warn("Failed to check username index", slog.String("error", err.Error()))
+		return
+	}
+	if !begun {
+		return
+	}
+
+	start := time.Now()
+	// Replicas may still list users that were deleted on the primary.
+	ctx = output.WithPrimaryReads(ctx)
+
+	var (
+		afterID int64
+		added   int
+	)
+	for {
+		usernames, lastID, err := b.repo.ListUsernames(ctx, afterID, usernameIndexBatchSize)
+		if err != nil {
+			b.log.Warn("Failed to list usernames for username index",
+				slog.Int64("after_id", afterID),
+				slog.String("error", err.Error()))
+			return
+		}
+		if len(usernames) == 0 {
+			break
+		}
+		if err := b.index.AddUsernames(ctx, usernames...); err != nil {
+			b.log.Warn("Failed to add usernames to username index", slog.String("error", err.Error()))
+			return
+		}
+		added += len(usernames)
+		afterID = lastID
+	}
+
+	if err := b.index.MarkComplete(ctx); err != nil {
+		b.log.Warn("Failed to complete username index build", slog.String("error", err.Error()))
+		return
+	}
+
+	b.log.Info("Username index built",
+		slog.Int("usernames", added),
+		slog.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"pinstack-user-service/internal/domain/models"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/repository/memory"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUsernameIndex mirrors the build states of the Redis username index.
+type fakeUsernameIndex struct {
+	mu        sync.Mutex
+	usernames map[string]struct{}
+	building  bool
+	complete  bool
+}
+
+func newFakeUsernameIndex() *fakeUsernameIndex {
+	return &fakeUsernameIndex{usernames: make(map[string]struct{})}
+}
+
+func (i *fakeUsernameIndex) AddUsernames(ctx context.Context, usernames ...string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, username := range usernames {
+		i.usernames[username] = struct{}{}
+	}
+	return nil
+}
+
+func (i *fakeUsernameIndex) RemoveUsername(ctx context.Context, username string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.usernames, username)
+	return nil
+}
+
+func (i *fakeUsernameIndex) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.complete {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	usernames := make([]string, 0)
+	for username := range i.usernames {
+		if strings.HasPrefix(strings.ToLower(username), strings.ToLower(prefix)) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames[:min(limit, len(usernames))], nil
+}
+
+func (i *fakeUsernameIndex) BeginBuild(ctx context.Context) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.complete {
+		return false, nil
+	}
+	i.building = true
+	return true, nil
+}
+
+func (i *fakeUsernameIndex) MarkComplete(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.building {
+		return errors.New("dropped")
+	}
+	i.building, i.complete = false, true
+	return nil
+}
+
+// drop loses the index the way Redis does when its key is deleted.
+func (i *fakeUsernameIndex) drop() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.usernames = make(map[string]struct{})
+	i.building, i.complete = false, false
+}
+
+// countingRepository counts ListUsernames calls.
+type countingRepository struct {
+	*memory.Repository
+	mu    sync.Mutex
+	lists int
+}
+
+func (r *countingRepository) ListUsernames(ctx context.Context, afterID int64, limit int) ([]string, int64, error) {
+	r.mu.Lock()
+	r.lists++
+	r.mu.Unlock()
+	return r.Repository.ListUsernames(ctx, afterID, limit)
+}
+
+func TestUsernameIndexBuilder_Build(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New("test")
+	repo := &countingRepository{Repository: memory.NewUserRepository(log)}
+	for _, username := range []string{"alice", "Alison", "bob"} {
+		_, err := repo.Create(ctx, &models.User{Username: username, Email: username + "@example.com"})
+		require.NoError(t, err)
+	}
+
+	index := newFakeUsernameIndex()
+	_, err := index.AutocompleteUsernames(ctx, "al", 10)
+	assert.ErrorIs(t, err, custom_errors.ErrCacheMiss, "an index that was never built is not served")
+
+	builder := NewUsernameIndexBuilder(repo, index, 0, log)
+	builder.Build(ctx)
+
+	usernames, err := index.AutocompleteUsernames(ctx, "al", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alison", "alice"}, usernames)
+
+	lists := repo.lists
+	builder.Build(ctx)
+	assert.Equal(t, lists, repo.lists, "a complete index is not rebuilt")
+
+	index.drop()
+	builder.Build(ctx)
+	usernames, err = index.AutocompleteUsernames(ctx, "b", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, usernames, "a lost index is rebuilt")
+}
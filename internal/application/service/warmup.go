@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"pinstack-user-service/internal/domain/models"
+	input "pinstack-user-service/internal/domain/ports/input"
+	output "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/domain/ports/output/cache"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// CacheWarmer preloads the most read users into the cache, e.g. after Redis
+// was flushed or failed over, so the first requests do not all miss at once.
+// service must be the undecorated service: warm-up reads are not accesses
+// and must not be counted as such.
+type CacheWarmer struct {
+	service     input.UserService
+	userCache   cache.UserCache
+	tracker     cache.AccessTracker
+	users       int
+	batchSize   int
+	concurrency int
+	log         output.Logger
+}
+
+func NewCacheWarmer(
+	service input.UserService,
+	userCache cache.UserCache,
+	tracker cache.AccessTracker,
+	users, batchSize, concurrency int,
+	log output.Logger,
+) *CacheWarmer {
+	if batchSize <= 0 || batchSize > MaxBatchSize {
+		batchSize = MaxBatchSize
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &CacheWarmer{
+		service:     service,
+		userCache:   userCache,
+		tracker:     tracker,
+		users:       users,
+		batchSize:   batchSize,
+		concurrency: concurrency,
+		log:         log,
+	}
+}
+
+// WarmUp loads users that are not cached yet in batches, with a bounded
+// number of batches in flight. A failed batch is logged and skipped: warm-up
+// only saves cache misses, so it never fails startup. It returns once every
+// batch is done or ctx is.
+func (w *CacheWarmer) WarmUp(ctx context.Context) {
+	start := time.Now()
+
+	ids, err := w.tracker.MostAccessed(ctx, w.users)
+	if err != nil {
+		if !errors.Is(err, custom_errors.ErrCacheMiss) {
+			w.log.Warn("Failed to get most accessed users, skipping cache warm-up", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if len(ids) == 0 {
+		w.log.Info("No user accesses recorded yet, skipping cache warm-up")
+		return
+	}
+
+	var loaded, cached atomic.Int64
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(w.concurrency)
+
+	for begin := 0; begin < len(ids); begin += w.batchSize {
+		batch := ids[begin:min(begin+w.batchSize, len(ids))]
+		group.Go(func() error {
+			n, hits := w.warmBatch(groupCtx, batch)
+			loaded.Add(int64(n))
+			cached.Add(int64(hits))
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	w.log.Info("Cache warm-up finished",
+		slog.Int("users", len(ids)),
+		slog.Int64("loaded", loaded.Load()),
+		slog.Int64("already_cached", cached.Load()),
+		slog.Duration("duration", time.Since(start)),
+		slog.Bool("interrupted", ctx.Err() != nil))
+}
+
+// warmBatch returns how many users it loaded and how many were already
+// cached.
+func (w *CacheWarmer) warmBatch(ctx context.Context, ids []int64) (int, int) {
+	if ctx.Err() != nil {
+		return 0, 0
+	}
+
+	present, err := w.userCache.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		present = map[int64]*models.User{}
+	}
+
+	misses := make([]int64, 0, len(ids)-len(present))
+	for _, id := range ids {
+		if _, ok := present[id]; !ok {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) == 0 {
+		return 0, len(present)
+	}
+
+	users, _, err := w.service.GetUsersByIDs(ctx, misses)
+	if err != nil {
+		w.log.Warn("Failed to load users for cache warm-up",
+			slog.Int("count", len(misses)),
+			slog.String("error", err.Error()))
+		return 0, len(present)
+	}
+
+	loaded := 0
+	for _, user := range users {
+		if err := w.userCache.FillUser(ctx, user); err != nil {
+			w.log.Warn("Failed to cache user during warm-up",
+				slog.Int64("user_id", user.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		loaded++
+	}
+	return loaded, len(present)
+}
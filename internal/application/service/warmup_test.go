@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"pinstack-user-service/internal/domain/models"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedAccessTracker []int64
+
+func (t fixedAccessTracker) RecordAccess(userIDs ...int64) {}
+
+func (t fixedAccessTracker) MostAccessed(ctx context.Context, limit int) ([]int64, error) {
+	return t[:min(limit, len(t))], nil
+}
+
+func TestCacheWarmer_LoadsUncachedUsersInBatches(t *testing.T) {
+	ctx := context.Background()
+	mockService := mocks.NewUserService(t)
+	userCache := newFakeUserCache()
+	require.NoError(t, userCache.SetUser(ctx, &models.User{ID: 2, Username: "cached", Email: "cached@example.com"}))
+
+	loadUsers := func(ids []int64) []*models.User {
+		users := make([]*models.User, 0, len(ids))
+		for _, id := range ids {
+			name := "user" + strconv.FormatInt(id, 10)
+			users = append(users, &models.User{ID: id, Username: name, Email: name + "@example.com"})
+		}
+		return users
+	}
+	mockService.On("GetUsersByIDs", mock.Anything, []int64{1, 3}).
+		Return(loadUsers([]int64{1, 3}), []int64{}, nil).
+		Once()
+	mockService.On("GetUsersByIDs", mock.Anything, []int64{4}).
+		Return(loadUsers([]int64{4}), []int64{}, nil).
+		Once()
+
+	warmer := NewCacheWarmer(mockService, userCache, fixedAccessTracker{1, 2, 3, 4, 5}, 4, 3, 2, logger.New("test"))
+	warmer.WarmUp(ctx)
+
+	for _, id := range []int64{1, 2, 3, 4} {
+		_, err := userCache.GetUserByID(ctx, id)
+		assert.NoError(t, err, "user %d should be cached", id)
+	}
+	_, err := userCache.GetUserByID(ctx, 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package models
+
+import "errors"
+
+// ErrVersionConflict is returned when an update names a version the user
+// no longer has, i.e. someone else modified it in between.
+var ErrVersionConflict = errors.New("user was modified concurrently")
@@ -0,0 +1,19 @@
+package models
+
+// SearchCursor is the position of the last row of a search page.
+// Cursor pages are ordered like offset pages, by rank descending, then
+// username and id, so the next page seeks strictly after this triple. Total
+// is the match count taken on the first page and carried forward, so later
+// pages do not recount the whole match set.
+type SearchCursor struct {
+	Rank     float64 `json:"r"`
+	Username string  `json:"u"`
+	ID       int64   `json:"i"`
+	Total    int     `json:"t"`
+}
+
+// SearchHit is a search result together with the cursor pointing at it.
+type SearchHit struct {
+	User   *User
+	Cursor SearchCursor
+}
@@ -13,4 +13,8 @@ type User struct {
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Version is incremented on every write. An update carrying a non-zero
+	// Version only applies if the stored row still has that version.
+	Version int64 `json:"version"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import "slices"
+
+// UserField names a User field that Update can write.
+type UserField string
+
+const (
+	UserFieldUsername UserField = "username"
+	UserFieldEmail    UserField = "email"
+	UserFieldFullName UserField = "full_name"
+	UserFieldBio      UserField = "bio"
+)
+
+// UpdatableUserFields lists every field Update accepts, in storage order.
+var UpdatableUserFields = UserFields{UserFieldUsername, UserFieldEmail, UserFieldFullName, UserFieldBio}
+
+// UserFields is the set of fields an update writes. Fields not named keep
+// their stored value; optional fields named here but nil on the user are
+// cleared.
+type UserFields []UserField
+
+func (f UserFields) Has(field UserField) bool {
+	return slices.Contains(f, field)
+}
@@ -9,11 +9,15 @@ import (
 type UserService interface {
 	Create(ctx context.Context, user *models.User) (*models.User, error)
 	Get(ctx context.Context, id int64) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*models.User, []int64, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
-	Update(ctx context.Context, user *models.User) (*models.User, error)
+	Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error)
 	Delete(ctx context.Context, id int64) error
-	Search(ctx context.Context, query string, page, limit int) ([]*models.User, int, error)
+	Search(ctx context.Context, query string, offset, limit int) ([]*models.User, int, error)
+	SearchPage(ctx context.Context, query, pageToken string, limit int) ([]*models.User, int, string, error)
 	UpdatePassword(ctx context.Context, id int64, oldPassword, newPassword string) error
 	UpdateAvatar(ctx context.Context, id int64, avatarURL string) error
+	VerifyCredentials(ctx context.Context, login, password string) (*models.User, error)
+	AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error)
 }
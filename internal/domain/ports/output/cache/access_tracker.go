@@ -0,0 +1,13 @@
+package cache
+
+import "context"
+
+//go:generate mockery --name AccessTracker --dir . --output ../../../../../mocks --outpkg mocks --with-expecter --filename AccessTracker.go
+
+// AccessTracker counts how often users are read so the most read ones can be
+// preloaded into an empty cache. RecordAccess must be cheap enough to call on
+// every read; counts may be recorded asynchronously and lost on failure.
+type AccessTracker interface {
+	RecordAccess(userIDs ...int64)
+	MostAccessed(ctx context.Context, limit int) ([]int64, error)
+}
@@ -0,0 +1,14 @@
+package cache
+
+import "context"
+
+//go:generate mockery --name Invalidator --dir . --output ../../../../../mocks --outpkg mocks --with-expecter --filename Invalidator.go
+
+// Invalidator broadcasts cache invalidation messages to every service instance.
+// Subscribe delivers messages published by any instance, including this one,
+// until ctx is done. Delivery is best effort: messages sent while an instance
+// is disconnected are lost.
+type Invalidator interface {
+	Publish(ctx context.Context, message []byte) error
+	Subscribe(ctx context.Context, handler func(message []byte)) error
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+
+	"pinstack-user-service/internal/domain/models"
+)
+
+// SearchKey identifies one page of search results. Query is expected to be
+// normalized by the caller; offset-based pages leave PageToken empty and
+// token-based pages leave Offset zero.
+type SearchKey struct {
+	Query     string
+	Offset    int
+	PageToken string
+	Limit     int
+}
+
+type SearchPage struct {
+	Users         []*models.User
+	Total         int
+	NextPageToken string
+}
+
+// SearchCache stores search result pages under a global generation. Every
+// user write bumps the generation, so pages cached before it are never read
+// again and simply expire; nothing has to find and delete them.
+// Generation and GetPage return custom_errors.ErrCacheMiss when the cache
+// cannot answer, in which case the caller should not store the page either.
+//
+//go:generate mockery --name SearchCache --dir . --output ../../../../../mocks --outpkg mocks --with-expecter --filename SearchCache.go
+type SearchCache interface {
+	Generation(ctx context.Context) (int64, error)
+	BumpGeneration(ctx context.Context) error
+	GetPage(ctx context.Context, generation int64, key SearchKey) (*SearchPage, error)
+	SetPage(ctx context.Context, generation int64, key SearchKey, page *SearchPage) error
+}
@@ -2,15 +2,37 @@ package cache
 
 import (
 	"context"
+
 	"pinstack-user-service/internal/domain/models"
 )
 
-//go:generate mockery --name UserCache --dir . --output ../../../../mocks/cache --outpkg mocks --with-expecter --filename UserCache.go
+// UserCache lookups return custom_errors.ErrCacheMiss when nothing is cached
+// and custom_errors.ErrUserNotFound when a not-found entry is cached.
+// Writes are versioned by the row version: SetUser uses the user's Version and
+// SetUserNotFoundByID takes the version the delete produced, and an entry is
+// never overwritten by an older version than the one it holds.
+// SetUser records a write, so tiers kept on other instances drop their copies;
+// FillUser caches a user that was only read and must be used on read paths,
+// where telling every instance to drop the user would defeat their caches.
+// Likewise SetUserNotFoundByID and the Release methods record writes (deletes,
+// renames), while the FillUserNotFound methods cache a read that found
+// nothing: such a read cannot be ordered against concurrent writes, so it only
+// fills an empty key and any later write replaces it.
+//
+//go:generate mockery --name UserCache --dir . --output ../../../../../mocks --outpkg mocks --with-expecter --filename UserCache.go
 type UserCache interface {
 	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 	SetUser(ctx context.Context, user *models.User) error
+	FillUser(ctx context.Context, user *models.User) error
+	SetUserNotFoundByID(ctx context.Context, userID int64, version int64) error
+	ReleaseUsername(ctx context.Context, username string) error
+	ReleaseEmail(ctx context.Context, email string) error
+	FillUserNotFoundByID(ctx context.Context, userID int64) error
+	FillUserNotFoundByUsername(ctx context.Context, username string) error
+	FillUserNotFoundByEmail(ctx context.Context, email string) error
 	DeleteUser(ctx context.Context, user *models.User) error
 	DeleteUserByID(ctx context.Context, userID int64) error
 }
@@ -0,0 +1,22 @@
+package cache
+
+import "context"
+
+//go:generate mockery --name UsernameIndex --dir . --output ../../../../../mocks --outpkg mocks --with-expecter --filename UsernameIndex.go
+
+// UsernameIndex keeps a lexicographically sorted set of usernames for prefix lookups.
+//
+// The index is authoritative once it is complete: a build copies every
+// username into it between BeginBuild and MarkComplete, and writes keep it up
+// to date from then on. AutocompleteUsernames returns custom_errors.ErrCacheMiss
+// while the index is not complete, e.g. before the first build or after the
+// cache lost it. BeginBuild reports false when the index is already complete
+// or cannot be written, and MarkComplete fails if the index was dropped while
+// the build was running.
+type UsernameIndex interface {
+	AddUsernames(ctx context.Context, usernames ...string) error
+	RemoveUsername(ctx context.Context, username string) error
+	AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error)
+	BeginBuild(ctx context.Context) (bool, error)
+	MarkComplete(ctx context.Context) error
+}
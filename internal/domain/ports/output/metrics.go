@@ -6,12 +6,20 @@ type MetricsProvider interface {
 	IncrementGRPCRequests(method, status string)
 	RecordGRPCRequestDuration(method, status string, duration time.Duration)
 
-	IncrementDatabaseQueries(queryType string, success bool)
-	RecordDatabaseQueryDuration(queryType string, duration time.Duration)
+	IncrementDatabaseQueries(pool, queryType string, success bool)
+	RecordDatabaseQueryDuration(pool, queryType string, duration time.Duration)
+	SetDatabaseReplicaLag(pool string, lag time.Duration)
+	SetDatabasePoolConnections(pool string, acquired, idle, total, max int)
+	AddDatabasePoolAcquires(pool string, acquires, waits int64, waitTime time.Duration)
 
 	IncrementCacheHits()
+	IncrementCacheNegativeHits()
 	IncrementCacheMisses()
 	RecordCacheOperationDuration(operation string, duration time.Duration)
+	IncrementCacheCoalescedRequests(operation string)
+	IncrementCacheTierHits(tier string)
+	IncrementCacheTierMisses(tier string)
+	SetCacheAvailable(available bool)
 
 	IncrementUserOperations(operation string, success bool)
 	SetActiveConnections(count int)
@@ -0,0 +1,10 @@
+package output
+
+// PasswordHasher hashes and verifies user passwords.
+// NeedsRehash reports whether a stored hash was produced with outdated parameters
+// and should be replaced after the next successful verification.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	NeedsRehash(hash string) bool
+}
@@ -0,0 +1,19 @@
+package output
+
+import "context"
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads marks ctx so that repository reads made with it see every
+// committed write. Repositories that spread reads over replicas send them to
+// the primary instead; use it for results that get shared, where a lagging
+// replica's answer would outlive the write that made it stale.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether ctx was marked by WithPrimaryReads.
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,9 @@
+package output
+
+import "context"
+
+// TxManager runs fn as one unit of work: repository calls made with the
+// context fn receives either all take effect or none do.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
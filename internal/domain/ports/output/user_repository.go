@@ -9,11 +9,19 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) (*models.User, error)
 	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
-	Update(ctx context.Context, user *models.User) (*models.User, error)
+	GetCredentialsByID(ctx context.Context, id int64) (*models.User, error)
+	GetCredentialsByUsername(ctx context.Context, username string) (*models.User, error)
+	GetCredentialsByEmail(ctx context.Context, email string) (*models.User, error)
+	GetCredentialsByIDForUpdate(ctx context.Context, id int64) (*models.User, error)
+	Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error)
 	Delete(ctx context.Context, id int64) error
 	Search(ctx context.Context, searchQuery string, offset, pageSize int) ([]*models.User, int, error)
+	SearchAfter(ctx context.Context, searchQuery string, cursor *models.SearchCursor, pageSize int) ([]*models.SearchHit, int, error)
 	UpdatePassword(ctx context.Context, id int64, newPassword string) error
 	UpdateAvatar(ctx context.Context, id int64, avatarURL string) error
+	AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error)
+	ListUsernames(ctx context.Context, afterID int64, limit int) ([]string, int64, error)
 }
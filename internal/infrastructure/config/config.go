@@ -2,7 +2,9 @@ package config
 
 import (
 	"log"
+	"math"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +14,10 @@ type Config struct {
 	GRPCServer GRPCServer
 	Database   Database
 	Redis      Redis
+	LocalCache LocalCache
+	Warmup     Warmup
+	Hasher     Hasher
+	Search     Search
 	Prometheus Prometheus
 }
 
@@ -27,14 +33,159 @@ type Database struct {
 	Port           string
 	DbName         string
 	MigrationsPath string
+
+	// SSLMode is a libpq sslmode ("disable", "require", "verify-ca",
+	// "verify-full"); SSLRootCert is the CA file the verify modes check the
+	// server certificate against. Both also apply to replica connections
+	// whose DSN does not set them itself.
+	SSLMode     string
+	SSLRootCert string
+
+	// ApplicationName and StatementTimeout are set on every connection,
+	// including replica connections whose DSN does not set them itself. Zero
+	// StatementTimeout leaves statements unbounded.
+	ApplicationName  string
+	StatementTimeout time.Duration
+
+	// Pool settings apply to the primary and every replica pool; zero keeps
+	// the pgxpool default.
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// PoolStatsInterval is how often pool statistics are published as
+	// metrics.
+	PoolStatsInterval time.Duration
+
+	// Replicas are DSNs of read replicas that plain reads are spread over.
+	// A replica that is not a standby streaming from the primary, whose lag
+	// exceeds ReplicaMaxLag (zero accepts any lag) or whose check fails stops
+	// serving reads until it recovers. The check reads pg_stat_wal_receiver,
+	// so the replica user needs pg_monitor or pg_read_all_stats.
+	Replicas             []string
+	ReplicaMaxLag        time.Duration
+	ReplicaCheckInterval time.Duration
+
+	// ReadYourWritesWindow is how long reads of a user this instance wrote
+	// keep going to the primary.
+	ReadYourWritesWindow time.Duration
 }
 
 type Redis struct {
-	Address  string
-	Port     int
+	// Mode is "standalone", "sentinel" or "cluster". Standalone connects to
+	// Address:Port; sentinel asks the sentinels in Addresses for the
+	// current master of MasterName; cluster uses Addresses as seed nodes
+	// and ignores DB.
+	Mode       string
+	Address    string
+	Port       int
+	Addresses  []string
+	MasterName string
+
+	// Username enables ACL authentication; leave it empty for requirepass.
+	Username string
 	Password string
+
+	SentinelUsername string
+	SentinelPassword string
+
 	DB       int
 	PoolSize int
+	TLS      RedisTLS
+
+	// UserTTL is how long user entries are cached and NotFoundTTL how long
+	// lookups that found no user are; zero NotFoundTTL disables negative
+	// caching. Both are spread by up to ±TTLJitterPercent so entries written
+	// together, e.g. by one search, do not expire together.
+	UserTTL          time.Duration
+	NotFoundTTL      time.Duration
+	TTLJitterPercent int
+
+	// SlidingExpiry resets a user entry's TTL when it is read in the second
+	// half of its TTL, keeping frequently read users cached. Their freshness
+	// then relies on writes updating the cache, and early refresh does not
+	// apply to them. Not-found entries never slide, which requires
+	// NotFoundTTL, jitter included, to stay below half of UserTTL.
+	SlidingExpiry bool
+
+	// SearchPopulatesCache caches every user returned by Search.
+	SearchPopulatesCache bool
+
+	// SearchPageTTL is how long whole search result pages are cached; zero
+	// disables page caching. Any user write makes all cached pages stale.
+	SearchPageTTL time.Duration
+
+	// UsernameIndexCheckInterval is how often the username index is checked
+	// and, if Redis lost it, rebuilt from the database.
+	UsernameIndexCheckInterval time.Duration
+
+	// Codec is "protobuf" or "json". Entries written by either are readable
+	// whatever is configured, so it can be switched without a flush.
+	// Encoded entries larger than CompressAbove bytes are compressed; zero
+	// disables compression.
+	Codec         string
+	CompressAbove int
+
+	// EarlyRefreshBeta and EarlyRefreshDelta tune probabilistic early
+	// expiration (XFetch): a read turns into a miss before the TTL runs out
+	// with a probability that grows as expiry approaches. Beta 0 disables it.
+	EarlyRefreshBeta  float64
+	EarlyRefreshDelta time.Duration
+
+	// BreakerThreshold consecutive connection failures stop all calls to
+	// Redis until a ping, sent every BreakerProbeInterval, succeeds again.
+	BreakerThreshold     int
+	BreakerProbeInterval time.Duration
+}
+
+// LocalCache configures the in-process cache in front of Redis. Entries are
+// dropped on every instance through the Redis invalidation channel, and TTL
+// bounds how stale an entry can get if an invalidation message is lost.
+type LocalCache struct {
+	Enabled             bool
+	Size                int
+	TTL                 time.Duration
+	InvalidationChannel string
+}
+
+// Warmup preloads the Users most read users into Redis before the server
+// starts accepting requests, BatchSize at a time with at most Concurrency
+// batches in flight, giving up after Timeout. Reads are counted all the time,
+// whether or not warm-up is enabled, in one set per AccessWindow trimmed to
+// TrackedUsers users and updated every FlushInterval. The most read users are
+// those with the most reads over the last AccessWindows windows, so counts
+// older than that no longer matter.
+type Warmup struct {
+	Enabled       bool
+	Users         int
+	BatchSize     int
+	Concurrency   int
+	Timeout       time.Duration
+	TrackedUsers  int
+	FlushInterval time.Duration
+	AccessWindow  time.Duration
+	AccessWindows int
+}
+
+type RedisTLS struct {
+	Enabled            bool
+	ServerName         string
+	CAFile             string
+	InsecureSkipVerify bool
+}
+
+type Hasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type Search struct {
+	PageTokenSecret string
 }
 
 type Prometheus struct {
@@ -58,12 +209,72 @@ func MustLoad() *Config {
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("database.db_name", "userservice")
 	viper.SetDefault("database.migrations_path", "migrations")
+	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.ssl_root_cert", "")
+	viper.SetDefault("database.application_name", "pinstack-user-service")
+	viper.SetDefault("database.statement_timeout", 30*time.Second)
+	viper.SetDefault("database.max_conns", 10)
+	viper.SetDefault("database.min_conns", 2)
+	viper.SetDefault("database.max_conn_lifetime", time.Hour)
+	viper.SetDefault("database.max_conn_idle_time", 30*time.Minute)
+	viper.SetDefault("database.health_check_period", time.Minute)
+	viper.SetDefault("database.pool_stats_interval", 15*time.Second)
+	viper.SetDefault("database.replicas", []string{})
+	viper.SetDefault("database.replica_max_lag", 5*time.Second)
+	viper.SetDefault("database.replica_check_interval", 5*time.Second)
+	viper.SetDefault("database.read_your_writes_window", 5*time.Second)
 
+	viper.SetDefault("redis.mode", "standalone")
 	viper.SetDefault("redis.address", "redis")
 	viper.SetDefault("redis.port", 6379)
+	viper.SetDefault("redis.addresses", []string{})
+	viper.SetDefault("redis.master_name", "")
+	viper.SetDefault("redis.username", "")
 	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.sentinel_username", "")
+	viper.SetDefault("redis.sentinel_password", "")
 	viper.SetDefault("redis.db", 6)
 	viper.SetDefault("redis.pool_size", 10)
+	viper.SetDefault("redis.tls.enabled", false)
+	viper.SetDefault("redis.tls.server_name", "")
+	viper.SetDefault("redis.tls.ca_file", "")
+	viper.SetDefault("redis.tls.insecure_skip_verify", false)
+	viper.SetDefault("redis.user_ttl", 30*time.Minute)
+	viper.SetDefault("redis.not_found_ttl", time.Minute)
+	viper.SetDefault("redis.ttl_jitter_percent", 10)
+	viper.SetDefault("redis.sliding_expiry", false)
+	viper.SetDefault("redis.search_populates_cache", true)
+	viper.SetDefault("redis.search_page_ttl", time.Minute)
+	viper.SetDefault("redis.username_index_check_interval", time.Minute)
+	viper.SetDefault("redis.codec", "protobuf")
+	viper.SetDefault("redis.compress_above", 1024)
+	viper.SetDefault("redis.early_refresh_beta", 1.0)
+	viper.SetDefault("redis.early_refresh_delta", 100*time.Millisecond)
+	viper.SetDefault("redis.breaker_threshold", 5)
+	viper.SetDefault("redis.breaker_probe_interval", time.Second)
+
+	viper.SetDefault("local_cache.enabled", true)
+	viper.SetDefault("local_cache.size", 10000)
+	viper.SetDefault("local_cache.ttl", 5*time.Second)
+	viper.SetDefault("local_cache.invalidation_channel", "user:invalidations")
+
+	viper.SetDefault("warmup.enabled", false)
+	viper.SetDefault("warmup.users", 10000)
+	viper.SetDefault("warmup.batch_size", 100)
+	viper.SetDefault("warmup.concurrency", 4)
+	viper.SetDefault("warmup.timeout", 30*time.Second)
+	viper.SetDefault("warmup.tracked_users", 100000)
+	viper.SetDefault("warmup.flush_interval", time.Second)
+	viper.SetDefault("warmup.access_window", time.Hour)
+	viper.SetDefault("warmup.access_windows", 24)
+
+	viper.SetDefault("hasher.memory", 64*1024)
+	viper.SetDefault("hasher.iterations", 3)
+	viper.SetDefault("hasher.parallelism", 2)
+	viper.SetDefault("hasher.salt_length", 16)
+	viper.SetDefault("hasher.key_length", 32)
+
+	viper.SetDefault("search.page_token_secret", "")
 
 	viper.SetDefault("prometheus.address", "0.0.0.0")
 	viper.SetDefault("prometheus.port", 9101)
@@ -73,6 +284,14 @@ func MustLoad() *Config {
 		os.Exit(1)
 	}
 
+	// Parallelism is a uint8; read it wide so an out of range value is
+	// rejected instead of wrapping around.
+	parallelism := viper.GetUint("hasher.parallelism")
+	if parallelism > math.MaxUint8 {
+		log.Printf("Invalid config: hasher.parallelism must be at most %d, got %d", math.MaxUint8, parallelism)
+		os.Exit(1)
+	}
+
 	config := &Config{
 		Env: viper.GetString("env"),
 		GRPCServer: GRPCServer{
@@ -86,13 +305,93 @@ func MustLoad() *Config {
 			Port:           viper.GetString("database.port"),
 			DbName:         viper.GetString("database.db_name"),
 			MigrationsPath: viper.GetString("database.migrations_path"),
+
+			SSLMode:     viper.GetString("database.sslmode"),
+			SSLRootCert: viper.GetString("database.ssl_root_cert"),
+
+			ApplicationName:  viper.GetString("database.application_name"),
+			StatementTimeout: viper.GetDuration("database.statement_timeout"),
+
+			MaxConns:          viper.GetInt32("database.max_conns"),
+			MinConns:          viper.GetInt32("database.min_conns"),
+			MaxConnLifetime:   viper.GetDuration("database.max_conn_lifetime"),
+			MaxConnIdleTime:   viper.GetDuration("database.max_conn_idle_time"),
+			HealthCheckPeriod: viper.GetDuration("database.health_check_period"),
+
+			PoolStatsInterval: viper.GetDuration("database.pool_stats_interval"),
+
+			Replicas:             viper.GetStringSlice("database.replicas"),
+			ReplicaMaxLag:        viper.GetDuration("database.replica_max_lag"),
+			ReplicaCheckInterval: viper.GetDuration("database.replica_check_interval"),
+			ReadYourWritesWindow: viper.GetDuration("database.read_your_writes_window"),
 		},
 		Redis: Redis{
-			Address:  viper.GetString("redis.address"),
-			Port:     viper.GetInt("redis.port"),
+			Mode:       viper.GetString("redis.mode"),
+			Address:    viper.GetString("redis.address"),
+			Port:       viper.GetInt("redis.port"),
+			Addresses:  viper.GetStringSlice("redis.addresses"),
+			MasterName: viper.GetString("redis.master_name"),
+
+			Username: viper.GetString("redis.username"),
 			Password: viper.GetString("redis.password"),
+
+			SentinelUsername: viper.GetString("redis.sentinel_username"),
+			SentinelPassword: viper.GetString("redis.sentinel_password"),
+
 			DB:       viper.GetInt("redis.db"),
 			PoolSize: viper.GetInt("redis.pool_size"),
+			TLS: RedisTLS{
+				Enabled:            viper.GetBool("redis.tls.enabled"),
+				ServerName:         viper.GetString("redis.tls.server_name"),
+				CAFile:             viper.GetString("redis.tls.ca_file"),
+				InsecureSkipVerify: viper.GetBool("redis.tls.insecure_skip_verify"),
+			},
+
+			UserTTL:          viper.GetDuration("redis.user_ttl"),
+			NotFoundTTL:      viper.GetDuration("redis.not_found_ttl"),
+			TTLJitterPercent: viper.GetInt("redis.ttl_jitter_percent"),
+
+			SlidingExpiry:        viper.GetBool("redis.sliding_expiry"),
+			SearchPopulatesCache: viper.GetBool("redis.search_populates_cache"),
+			SearchPageTTL:        viper.GetDuration("redis.search_page_ttl"),
+
+			UsernameIndexCheckInterval: viper.GetDuration("redis.username_index_check_interval"),
+
+			Codec:         viper.GetString("redis.codec"),
+			CompressAbove: viper.GetInt("redis.compress_above"),
+
+			EarlyRefreshBeta:  viper.GetFloat64("redis.early_refresh_beta"),
+			EarlyRefreshDelta: viper.GetDuration("redis.early_refresh_delta"),
+
+			BreakerThreshold:     viper.GetInt("redis.breaker_threshold"),
+			BreakerProbeInterval: viper.GetDuration("redis.breaker_probe_interval"),
+		},
+		LocalCache: LocalCache{
+			Enabled:             viper.GetBool("local_cache.enabled"),
+			Size:                viper.GetInt("local_cache.size"),
+			TTL:                 viper.GetDuration("local_cache.ttl"),
+			InvalidationChannel: viper.GetString("local_cache.invalidation_channel"),
+		},
+		Warmup: Warmup{
+			Enabled:       viper.GetBool("warmup.enabled"),
+			Users:         viper.GetInt("warmup.users"),
+			BatchSize:     viper.GetInt("warmup.batch_size"),
+			Concurrency:   viper.GetInt("warmup.concurrency"),
+			Timeout:       viper.GetDuration("warmup.timeout"),
+			TrackedUsers:  viper.GetInt("warmup.tracked_users"),
+			FlushInterval: viper.GetDuration("warmup.flush_interval"),
+			AccessWindow:  viper.GetDuration("warmup.access_window"),
+			AccessWindows: viper.GetInt("warmup.access_windows"),
+		},
+		Hasher: Hasher{
+			Memory:      viper.GetUint32("hasher.memory"),
+			Iterations:  viper.GetUint32("hasher.iterations"),
+			Parallelism: uint8(parallelism),
+			SaltLength:  viper.GetUint32("hasher.salt_length"),
+			KeyLength:   viper.GetUint32("hasher.key_length"),
+		},
+		Search: Search{
+			PageTokenSecret: viper.GetString("search.page_token_secret"),
 		},
 		Prometheus: Prometheus{
 			Address: viper.GetString("prometheus.address"),
@@ -100,5 +399,10 @@ func MustLoad() *Config {
 		},
 	}
 
+	if err := validate(config); err != nil {
+		log.Printf("Invalid config: %s", err)
+		os.Exit(1)
+	}
+
 	return config
 }
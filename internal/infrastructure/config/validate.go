@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// placeholderSecrets are values copied from examples and docs; a page token
+// secret anyone can guess lets clients forge tokens.
+var placeholderSecrets = []string{"change-me", "changeme", "secret", "example", "default", "password"}
+
+// minPageTokenSecretLength is the shortest page token secret accepted.
+const minPageTokenSecretLength = 16
+
+// validate rejects settings that would only fail, or misbehave, once the
+// service is running.
+func validate(c *Config) error {
+	return errors.Join(
+		validateHasher(c.Hasher),
+		validateRedis(c.Redis),
+		validateSearch(c.Search),
+	)
+}
+
+func validateHasher(h Hasher) error {
+	var errs []error
+	if h.Parallelism == 0 {
+		errs = append(errs, errors.New("hasher.parallelism must be at least 1"))
+	}
+	if h.Iterations == 0 {
+		errs = append(errs, errors.New("hasher.iterations must be at least 1"))
+	}
+	// argon2 needs 8 KiB of memory per lane.
+	if h.Memory < 8*uint32(h.Parallelism) {
+		errs = append(errs, fmt.Errorf("hasher.memory must be at least 8 KiB per lane (%d KiB), got %d", 8*uint32(h.Parallelism), h.Memory))
+	}
+	if h.SaltLength < 8 {
+		errs = append(errs, fmt.Errorf("hasher.salt_length must be at least 8 bytes, got %d", h.SaltLength))
+	}
+	if h.KeyLength < 16 {
+		errs = append(errs, fmt.Errorf("hasher.key_length must be at least 16 bytes, got %d", h.KeyLength))
+	}
+	return errors.Join(errs...)
+}
+
+func validateRedis(r Redis) error {
+	var errs []error
+	if r.TTLJitterPercent < 0 || r.TTLJitterPercent > 100 {
+		errs = append(errs, fmt.Errorf("redis.ttl_jitter_percent must be between 0 and 100, got %d", r.TTLJitterPercent))
+	}
+	// Sliding reads tell tombstones from users by their TTL alone.
+	maxNotFoundTTL := r.NotFoundTTL + r.NotFoundTTL*time.Duration(r.TTLJitterPercent)/100
+	if r.SlidingExpiry && maxNotFoundTTL >= r.UserTTL/2 {
+		errs = append(errs, fmt.Errorf("redis.sliding_expiry needs not_found_ttl plus jitter (%s) below half of user_ttl (%s)", maxNotFoundTTL, r.UserTTL/2))
+	}
+	return errors.Join(errs...)
+}
+
+// validateSearch requires a page token secret: every instance has to share
+// it, or a page token issued by one replica is rejected by the next.
+func validateSearch(s Search) error {
+	secret := s.PageTokenSecret
+	if secret == "" {
+		return errors.New("search.page_token_secret is required")
+	}
+	for _, placeholder := range placeholderSecrets {
+		if strings.EqualFold(strings.TrimSpace(secret), placeholder) {
+			return fmt.Errorf("search.page_token_secret is the placeholder %q; set a random secret", secret)
+		}
+	}
+	if len(secret) < minPageTokenSecretLength {
+		return fmt.Errorf("search.page_token_secret must be at least %d bytes, got %d", minPageTokenSecretLength, len(secret))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Hasher: Hasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32},
+		Redis:  Redis{UserTTL: 30 * time.Minute, NotFoundTTL: time.Minute, TTLJitterPercent: 10, SlidingExpiry: true},
+		Search: Search{PageTokenSecret: "0123456789abcdef0123456789abcdef"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, validate(validConfig()))
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"zero parallelism", func(c *Config) { c.Hasher.Parallelism = 0 }, "hasher.parallelism"},
+		{"zero iterations", func(c *Config) { c.Hasher.Iterations = 0 }, "hasher.iterations"},
+		{"too little memory", func(c *Config) { c.Hasher.Memory = 8 }, "hasher.memory"},
+		{"short salt", func(c *Config) { c.Hasher.SaltLength = 4 }, "hasher.salt_length"},
+		{"short key", func(c *Config) { c.Hasher.KeyLength = 8 }, "hasher.key_length"},
+		{"negative jitter", func(c *Config) { c.Redis.TTLJitterPercent = -1 }, "redis.ttl_jitter_percent"},
+		{"jitter above 100", func(c *Config) { c.Redis.TTLJitterPercent = 150 }, "redis.ttl_jitter_percent"},
+		{"sliding with long not found ttl", func(c *Config) { c.Redis.NotFoundTTL = 15 * time.Minute }, "redis.sliding_expiry"},
+		{"missing page token secret", func(c *Config) { c.Search.PageTokenSecret = "" }, "search.page_token_secret"},
+		{"placeholder page token secret", func(c *Config) { c.Search.PageTokenSecret = "change-me" }, "placeholder"},
+		{"short page token secret", func(c *Config) { c.Search.PageTokenSecret = "s3cr3t" }, "search.page_token_secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.modify(c)
+			err := validate(c)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -352,11 +354,36 @@ func TestUserGRPCService_GetUserByEmail(t *testing.T) {
 				assert.Equal(t, tt.want.Username, got.Username)
 				assert.Equal(t, tt.want.Email, got.Email)
 				assert.Equal(t, tt.want.FullName, got.FullName)
+				assert.Empty(t, got.Password)
 			}
 		})
 	}
 }
 
+func TestUserGRPCService_GetUserByEmail_Password(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.PasswordMetadataKey, "password123"))
+	mockService.EXPECT().VerifyCredentials(ctx, "test@example.com", "password123").
+		Return(&models.User{ID: 1, Username: "testuser", Email: "test@example.com"}, nil)
+
+	got, err := handler.GetUserByEmail(ctx, &pb.GetUserByEmailRequest{Email: "test@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Id)
+	assert.Empty(t, got.Password)
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.PasswordMetadataKey, "wrong"))
+		mockService.EXPECT().VerifyCredentials(ctx, "testuser", "wrong").
+			Return(nil, custom_errors.ErrInvalidCredentials)
+
+		got, err := handler.GetUserByUsername(ctx, &pb.GetUserByUsernameRequest{Username: "testuser"})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
 func TestUserGRPCService_UpdateUser(t *testing.T) {
 	handler, mockService, cleanup := setupTest(t)
 	defer cleanup()
@@ -385,6 +412,7 @@ func TestUserGRPCService_UpdateUser(t *testing.T) {
 						Email:    "updated@example.com",
 						FullName: stringPtr("Updated User"),
 					},
+					models.UserFields{models.UserFieldUsername, models.UserFieldEmail, models.UserFieldFullName},
 				).Return(&models.User{
 					ID:       1,
 					Username: "updateduser",
@@ -417,6 +445,7 @@ func TestUserGRPCService_UpdateUser(t *testing.T) {
 						Email:    "nonexistent@example.com",
 						FullName: stringPtr("Nonexistent User"),
 					},
+					models.UserFields{models.UserFieldUsername, models.UserFieldEmail, models.UserFieldFullName},
 				).Return(nil, custom_errors.ErrUserNotFound)
 			},
 			want:    nil,
@@ -522,10 +551,10 @@ func TestUserGRPCService_SearchUsers(t *testing.T) {
 				Limit:  10,
 			},
 			mock: func() {
-				mockService.EXPECT().Search(
+				mockService.EXPECT().SearchPage(
 					context.Background(),
 					"test",
-					0,
+					"",
 					10,
 				).Return([]*models.User{
 					{
@@ -544,7 +573,7 @@ func TestUserGRPCService_SearchUsers(t *testing.T) {
 						UpdatedAt: time.Now(),
 						FullName:  stringPtr("Test User 2"),
 					},
-				}, 2, nil)
+				}, 2, "", nil)
 			},
 			want: &pb.SearchUsersResponse{
 				Users: []*pb.User{
@@ -573,12 +602,12 @@ func TestUserGRPCService_SearchUsers(t *testing.T) {
 				Limit:  10,
 			},
 			mock: func() {
-				mockService.EXPECT().Search(
+				mockService.EXPECT().SearchPage(
 					context.Background(),
 					"nonexistent",
-					0,
+					"",
 					10,
-				).Return([]*models.User{}, 0, nil)
+				).Return([]*models.User{}, 0, "", nil)
 			},
 			want: &pb.SearchUsersResponse{
 				Users: []*pb.User{},
@@ -586,6 +615,27 @@ func TestUserGRPCService_SearchUsers(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "offset is passed through unchanged",
+			req: &pb.SearchUsersRequest{
+				Query:  "test",
+				Offset: 20,
+				Limit:  10,
+			},
+			mock: func() {
+				mockService.EXPECT().Search(
+					context.Background(),
+					"test",
+					20,
+					10,
+				).Return([]*models.User{}, 25, nil)
+			},
+			want: &pb.SearchUsersResponse{
+				Users: []*pb.User{},
+				Total: 25,
+			},
+			wantErr: nil,
+		},
 		{
 			name: "invalid page size",
 			req: &pb.SearchUsersRequest{
@@ -637,6 +687,194 @@ func TestUserGRPCService_SearchUsers(t *testing.T) {
 	}
 }
 
+type headerCapturingStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerCapturingStream) Method() string { return "/user.v1.UserService/SearchUsers" }
+
+func (s *headerCapturingStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestUserGRPCService_SearchUsers_PageToken(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	stream := &headerCapturingStream{}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.PageTokenMetadataKey, "token-1"))
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	mockService.EXPECT().SearchPage(ctx, "test", "token-1", 2).Return([]*models.User{
+		{ID: 3, Username: "testuser3", Email: "test3@example.com"},
+		{ID: 4, Username: "testuser4", Email: "test4@example.com"},
+	}, 5, "token-2", nil)
+
+	got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "test", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got.Total)
+	assert.Len(t, got.Users, 2)
+	assert.Equal(t, []string{"token-2"}, stream.header.Get(user_grpc.NextPageTokenMetadataKey))
+
+	t.Run("invalid token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.PageTokenMetadataKey, "forged"))
+		mockService.EXPECT().SearchPage(ctx, "test", "forged", 2).Return(nil, 0, "", custom_errors.ErrInvalidInput)
+
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "test", Limit: 2})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("token with offset", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.PageTokenMetadataKey, "token-1"))
+
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "test", Offset: 2, Limit: 2})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUserGRPCService_SearchUsers_UserIDs(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	stream := &headerCapturingStream{}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.UserIDsMetadataKey, "3, 1,999"))
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	mockService.EXPECT().GetUsersByIDs(ctx, []int64{3, 1, 999}).Return([]*models.User{
+		{ID: 3, Username: "third"},
+		{ID: 1, Username: "first"},
+	}, []int64{999}, nil)
+
+	got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{})
+	assert.NoError(t, err)
+	if assert.Len(t, got.Users, 2) {
+		assert.Equal(t, int64(3), got.Users[0].Id)
+		assert.Equal(t, int64(1), got.Users[1].Id)
+	}
+	assert.Equal(t, int64(2), got.Total)
+	assert.Equal(t, []string{"999"}, stream.header.Get(user_grpc.MissingUserIDsMetadataKey))
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.UserIDsMetadataKey, "1,abc"))
+
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("too many ids", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.UserIDsMetadataKey, "1,2"))
+		mockService.EXPECT().GetUsersByIDs(ctx, []int64{1, 2}).Return(nil, nil, custom_errors.ErrInvalidInput)
+
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUserGRPCService_SearchUsers_Autocomplete(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.AutocompleteMetadataKey, "true"))
+	mockService.EXPECT().AutocompleteUsernames(ctx, "ali", 5).Return([]string{"alice", "alicia"}, nil)
+
+	got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "ali", Limit: 5})
+	assert.NoError(t, err)
+	if assert.Len(t, got.Users, 2) {
+		assert.Equal(t, "alice", got.Users[0].Username)
+		assert.Equal(t, "alicia", got.Users[1].Username)
+	}
+	assert.Equal(t, int64(2), got.Total)
+
+	t.Run("offset", func(t *testing.T) {
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "ali", Offset: 5, Limit: 5})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.AutocompleteMetadataKey, "maybe"))
+
+		got, err := handler.SearchUsers(ctx, &pb.SearchUsersRequest{Query: "ali", Limit: 5})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUserGRPCService_UpdateUser_IfMatch(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	stream := &headerCapturingStream{}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.IfMatchMetadataKey, `"3"`))
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	mockService.EXPECT().Update(ctx, &models.User{ID: 1, Username: "updateduser", Version: 3}, models.UserFields{models.UserFieldUsername}).
+		Return(&models.User{ID: 1, Username: "updateduser", Version: 4}, nil)
+
+	got, err := handler.UpdateUser(ctx, &pb.UpdateUserRequest{Id: 1, Username: strPtr("updateduser")})
+	assert.NoError(t, err)
+	assert.Equal(t, "updateduser", got.Username)
+	assert.Equal(t, []string{`"4"`}, stream.header.Get(user_grpc.ETagMetadataKey))
+
+	t.Run("stale version", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.IfMatchMetadataKey, "2"))
+		mockService.EXPECT().Update(ctx, &models.User{ID: 1, Username: "other", Version: 2}, models.UserFields{models.UserFieldUsername}).
+			Return(nil, models.ErrVersionConflict)
+
+		got, err := handler.UpdateUser(ctx, &pb.UpdateUserRequest{Id: 1, Username: strPtr("other")})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+	})
+
+	t.Run("invalid if-match", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.IfMatchMetadataKey, "abc"))
+
+		got, err := handler.UpdateUser(ctx, &pb.UpdateUserRequest{Id: 1, Username: strPtr("other")})
+		assert.Nil(t, got)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUserGRPCService_UpdateUser_UpdateMask(t *testing.T) {
+	handler, mockService, cleanup := setupTest(t)
+	defer cleanup()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.UpdateMaskMetadataKey, "bio, full_name"))
+	mockService.EXPECT().Update(ctx, &models.User{ID: 1, FullName: stringPtr("Alice")},
+		models.UserFields{models.UserFieldBio, models.UserFieldFullName}).
+		Return(&models.User{ID: 1, Username: "alice", FullName: stringPtr("Alice")}, nil)
+
+	// Bio is masked but unset, so it is cleared; username is set but not
+	// masked, so it is left alone.
+	got, err := handler.UpdateUser(ctx, &pb.UpdateUserRequest{Id: 1, Username: strPtr("ignored"), FullName: strPtr("Alice")})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", got.Username)
+	assert.Nil(t, got.Bio)
+
+	for _, tt := range []struct {
+		name string
+		mask string
+	}{
+		{name: "unknown field", mask: "password"},
+		{name: "id", mask: "id"},
+		{name: "clearing username", mask: "username"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(user_grpc.UpdateMaskMetadataKey, tt.mask))
+
+			got, err := handler.UpdateUser(ctx, &pb.UpdateUserRequest{Id: 1})
+			assert.Nil(t, got)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
 func TestUserGRPCService_UpdatePassword(t *testing.T) {
 	handler, mockService, cleanup := setupTest(t)
 	defer cleanup()
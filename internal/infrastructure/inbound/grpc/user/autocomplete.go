@@ -0,0 +1,60 @@
+package user_grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+
+	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UserService has no autocomplete RPC yet, so username suggestions ride on
+// SearchUsers: a request sent with x-autocomplete: true treats the query as a
+// username prefix and returns up to limit users carrying only their username.
+// Autocomplete has a single page, so an offset or page token is rejected.
+const AutocompleteMetadataKey = "x-autocomplete"
+
+// autocompleteFromContext reports whether the client asked for username
+// suggestions.
+func autocompleteFromContext(ctx context.Context) (bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	values := md.Get(AutocompleteMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return false, nil
+	}
+	autocomplete, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid %s value %q", AutocompleteMetadataKey, values[0])
+	}
+	return autocomplete, nil
+}
+
+func (s *UserGRPCService) autocompleteUsernames(ctx context.Context, prefix string, limit int32) (*pb.SearchUsersResponse, error) {
+	usernames, err := s.userService.AutocompleteUsernames(ctx, prefix, int(limit))
+	if err != nil {
+		switch {
+		case errors.Is(err, custom_errors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	resp := &pb.SearchUsersResponse{
+		Users: make([]*pb.User, 0, len(usernames)),
+		Total: int64(len(usernames)),
+	}
+	for _, username := range usernames {
+		resp.Users = append(resp.Users, &pb.User{Username: username})
+	}
+	return resp, nil
+}
@@ -57,6 +57,7 @@ func (s *UserGRPCService) CreateUser(ctx context.Context, req *pb.CreateUserRequ
 		}
 	}
 
+	s.setETag(ctx, createdUser.Version)
 	resp := &pb.User{
 		Id:        createdUser.ID,
 		Username:  createdUser.Username,
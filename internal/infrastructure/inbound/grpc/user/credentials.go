@@ -0,0 +1,60 @@
+package user_grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+
+	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserService has no VerifyCredentials RPC yet, so credential checks ride on
+// the lookups: GetUserByEmail and GetUserByUsername sent with the password in
+// x-password verify it and return the user only if it matches. A wrong
+// password and a missing user both answer Unauthenticated, so the response
+// does not reveal whether the account exists. Password hashes never leave
+// the service.
+const PasswordMetadataKey = "x-password"
+
+// passwordFromContext returns the password sent with the request, and whether
+// the client sent one.
+func passwordFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(PasswordMetadataKey)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func (s *UserGRPCService) verifyCredentials(ctx context.Context, login, password string) (*pb.User, error) {
+	user, err := s.userService.VerifyCredentials(ctx, login, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, custom_errors.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	s.setETag(ctx, user.Version)
+	return &pb.User{
+		Id:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Bio:       user.Bio,
+		AvatarUrl: user.AvatarURL,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package user_grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// The User message has no version field, so optimistic concurrency travels in
+// metadata the way HTTP does it: reads return the user's version in the etag
+// response header, and UpdateUser applies only if the if-match request header
+// still names the current version. Without if-match the update is
+// unconditional, as before.
+const (
+	ETagMetadataKey    = "etag"
+	IfMatchMetadataKey = "if-match"
+)
+
+var errInvalidIfMatch = errors.New("if-match must be a positive version")
+
+// ifMatchFromContext returns the version named by if-match, or 0 if the
+// client sent none. Quoted ETags are accepted as sent back by setETag.
+func ifMatchFromContext(ctx context.Context) (int64, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+	values := md.Get(IfMatchMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(values[0], `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+func (s *UserGRPCService) setETag(ctx context.Context, version int64) {
+	if version <= 0 {
+		return
+	}
+	etag := strconv.Quote(strconv.FormatInt(version, 10))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(ETagMetadataKey, etag)); err != nil {
+		s.log.Debug("Failed to set etag header", slog.String("error", err.Error()))
+	}
+}
@@ -35,6 +35,7 @@ func (s *UserGRPCService) GetUser(ctx context.Context, req *pb.GetUserRequest) (
 
 	}
 
+	s.setETag(ctx, user.Version)
 	return &pb.User{
 		Id:        user.ID,
 		Username:  user.Username,
@@ -44,6 +45,5 @@ func (s *UserGRPCService) GetUser(ctx context.Context, req *pb.GetUserRequest) (
 		AvatarUrl: user.AvatarURL,
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
-		Password:  user.Password,
 	}, nil
 }
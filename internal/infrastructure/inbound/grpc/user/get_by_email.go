@@ -2,6 +2,9 @@ package user_grpc
 
 import (
 	"context"
+	"errors"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -20,11 +23,21 @@ func (s *UserGRPCService) GetUserByEmail(ctx context.Context, req *pb.GetUserByE
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if password, ok := passwordFromContext(ctx); ok {
+		return s.verifyCredentials(ctx, req.Email, password)
+	}
+
 	user, err := s.userService.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		switch {
+		case errors.Is(err, custom_errors.ErrUserNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	}
 
+	s.setETag(ctx, user.Version)
 	return &pb.User{
 		Id:        user.ID,
 		Username:  user.Username,
@@ -34,6 +47,5 @@ func (s *UserGRPCService) GetUserByEmail(ctx context.Context, req *pb.GetUserByE
 		AvatarUrl: user.AvatarURL,
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
-		Password:  user.Password,
 	}, nil
 }
@@ -0,0 +1,97 @@
+package user_grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+
+	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserService has no batch RPC yet, so batch lookups ride on SearchUsers: a
+// request sent with a comma-separated id list in x-user-ids returns those
+// users, in the order given, instead of search results; query, offset and
+// limit are ignored. Ids that do not exist are listed in the
+// x-missing-user-ids response header rather than failing the call.
+const (
+	UserIDsMetadataKey        = "x-user-ids"
+	MissingUserIDsMetadataKey = "x-missing-user-ids"
+)
+
+// userIDsFromContext returns the ids sent with the request, and whether the
+// client sent any.
+func userIDsFromContext(ctx context.Context) ([]int64, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, false, nil
+	}
+	values := md.Get(UserIDsMetadataKey)
+	if len(values) == 0 {
+		return nil, false, nil
+	}
+
+	ids := make([]int64, 0)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil || id <= 0 {
+				return nil, true, fmt.Errorf("invalid user id %q", part)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, true, nil
+}
+
+func (s *UserGRPCService) getUsersByIDs(ctx context.Context, ids []int64) (*pb.SearchUsersResponse, error) {
+	users, missing, err := s.userService.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		switch {
+		case errors.Is(err, custom_errors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if len(missing) > 0 {
+		parts := make([]string, 0, len(missing))
+		for _, id := range missing {
+			parts = append(parts, strconv.FormatInt(id, 10))
+		}
+		if err := grpc.SetHeader(ctx, metadata.Pairs(MissingUserIDsMetadataKey, strings.Join(parts, ","))); err != nil {
+			s.log.Debug("Failed to set missing user ids header", slog.String("error", err.Error()))
+		}
+	}
+
+	resp := &pb.SearchUsersResponse{
+		Users: make([]*pb.User, 0, len(users)),
+		Total: int64(len(users)),
+	}
+	for _, u := range users {
+		resp.Users = append(resp.Users, &pb.User{
+			Id:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			FullName:  u.FullName,
+			Bio:       u.Bio,
+			AvatarUrl: u.AvatarURL,
+			CreatedAt: timestamppb.New(u.CreatedAt),
+			UpdatedAt: timestamppb.New(u.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
@@ -22,6 +22,10 @@ func (s *UserGRPCService) GetUserByUsername(ctx context.Context, req *pb.GetUser
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	if password, ok := passwordFromContext(ctx); ok {
+		return s.verifyCredentials(ctx, req.Username, password)
+	}
+
 	user, err := s.userService.GetByUsername(ctx, req.Username)
 	if err != nil {
 		switch {
@@ -32,6 +36,7 @@ func (s *UserGRPCService) GetUserByUsername(ctx context.Context, req *pb.GetUser
 		}
 	}
 
+	s.setETag(ctx, user.Version)
 	return &pb.User{
 		Id:        user.ID,
 		Username:  user.Username,
@@ -41,6 +46,5 @@ func (s *UserGRPCService) GetUserByUsername(ctx context.Context, req *pb.GetUser
 		AvatarUrl: user.AvatarURL,
 		CreatedAt: timestamppb.New(user.CreatedAt),
 		UpdatedAt: timestamppb.New(user.UpdatedAt),
-		Password:  user.Password,
 	}, nil
 }
@@ -2,13 +2,28 @@ package user_grpc
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
+	"pinstack-user-service/internal/domain/models"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// SearchUsersRequest has no page token fields yet, so keyset pagination
+// travels in metadata: the client sends the token it got back in the
+// x-next-page-token response header as x-page-token.
+const (
+	PageTokenMetadataKey     = "x-page-token"
+	NextPageTokenMetadataKey = "x-next-page-token"
+)
+
 type SearchRequest struct {
 	Query  string `validate:"omitempty"`
 	Offset int32  `validate:"gte=0"`
@@ -16,6 +31,14 @@ type SearchRequest struct {
 }
 
 func (s *UserGRPCService) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	ids, batch, err := userIDsFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if batch {
+		return s.getUsersByIDs(ctx, ids)
+	}
+
 	input := SearchRequest{
 		Query:  req.Query,
 		Offset: req.Offset,
@@ -25,9 +48,45 @@ func (s *UserGRPCService) SearchUsers(ctx context.Context, req *pb.SearchUsersRe
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	users, total, err := s.userService.Search(ctx, req.Query, int(req.Offset), int(req.Limit))
+	pageToken := pageTokenFromContext(ctx)
+	autocomplete, err := autocompleteFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if autocomplete {
+		if req.Offset > 0 || pageToken != "" {
+			return nil, status.Error(codes.InvalidArgument, "autocomplete does not take an offset or page token")
+		}
+		return s.autocompleteUsernames(ctx, req.Query, req.Limit)
+	}
+
+	if pageToken != "" && req.Offset > 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset and page token are mutually exclusive")
+	}
+
+	var (
+		users         []*models.User
+		total         int
+		nextPageToken string
+	)
+	if req.Offset > 0 {
+		users, total, err = s.userService.Search(ctx, req.Query, int(req.Offset), int(req.Limit))
+	} else {
+		users, total, nextPageToken, err = s.userService.SearchPage(ctx, req.Query, pageToken, int(req.Limit))
+	}
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		switch {
+		case errors.Is(err, custom_errors.ErrInvalidInput):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if nextPageToken != "" {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(NextPageTokenMetadataKey, nextPageToken)); err != nil {
+			s.log.Debug("Failed to set next page token header", slog.String("error", err.Error()))
+		}
 	}
 
 	resp := &pb.SearchUsersResponse{
@@ -48,3 +107,15 @@ func (s *UserGRPCService) SearchUsers(ctx context.Context, req *pb.SearchUsersRe
 	}
 	return resp, nil
 }
+
+func pageTokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(PageTokenMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"pinstack-user-service/internal/utils"
 
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 
@@ -38,24 +37,38 @@ func (s *UserGRPCService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	version, err := ifMatchFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	mask, err := updateMaskFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fields, err := updateFields(req, mask)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	user := &models.User{
-		ID: req.Id,
+		ID:      req.Id,
+		Version: version,
 	}
 
-	if req.Username != nil {
-		user.Username = utils.StrPtrToStr(req.Username)
+	if fields.Has(models.UserFieldUsername) {
+		user.Username = req.GetUsername()
 	}
-	if req.Email != nil {
-		user.Email = utils.StrPtrToStr(req.Email)
+	if fields.Has(models.UserFieldEmail) {
+		user.Email = req.GetEmail()
 	}
-	if req.FullName != nil {
+	if fields.Has(models.UserFieldFullName) {
 		user.FullName = req.FullName
 	}
-	if req.Bio != nil {
+	if fields.Has(models.UserFieldBio) {
 		user.Bio = req.Bio
 	}
 
-	updatedUser, err := s.userService.Update(ctx, user)
+	updatedUser, err := s.userService.Update(ctx, user, fields)
 	if err != nil {
 		switch {
 		case errors.Is(err, custom_errors.ErrUserNotFound):
@@ -63,11 +76,14 @@ func (s *UserGRPCService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 		case errors.Is(err, custom_errors.ErrUsernameExists), errors.Is(err, custom_errors.ErrEmailExists):
 			s.log.Debug("Email or Username already exists received in grpc", slog.String("error", err.Error()))
 			return nil, status.Error(codes.AlreadyExists, err.Error())
+		case errors.Is(err, models.ErrVersionConflict):
+			return nil, status.Error(codes.Aborted, err.Error())
 		default:
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	}
 
+	s.setETag(ctx, updatedUser.Version)
 	return &pb.User{
 		Id:        updatedUser.ID,
 		Username:  updatedUser.Username,
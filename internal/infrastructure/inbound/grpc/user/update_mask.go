@@ -0,0 +1,92 @@
+package user_grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pinstack-user-service/internal/domain/models"
+
+	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// UpdateUserRequest has no update_mask field yet, so the google.protobuf.FieldMask
+// travels in metadata as comma-separated paths, e.g. "bio,full_name". Masked
+// fields are written exactly, so an optional field named in the mask but left
+// unset in the request is cleared. Without a mask every field set in the
+// request is written, as before.
+const UpdateMaskMetadataKey = "x-update-mask"
+
+var maskFields = map[string]models.UserField{
+	"username":  models.UserFieldUsername,
+	"email":     models.UserFieldEmail,
+	"full_name": models.UserFieldFullName,
+	"bio":       models.UserFieldBio,
+}
+
+// updateMaskFromContext returns the mask sent with the request, or nil if
+// there is none.
+func updateMaskFromContext(ctx context.Context) (*fieldmaskpb.FieldMask, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	values := md.Get(UpdateMaskMetadataKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var paths []string
+	for _, value := range values {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	mask, err := fieldmaskpb.New(&pb.UpdateUserRequest{}, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update mask: %w", err)
+	}
+	mask.Normalize()
+	return mask, nil
+}
+
+// updateFields resolves which user fields req writes.
+func updateFields(req *pb.UpdateUserRequest, mask *fieldmaskpb.FieldMask) (models.UserFields, error) {
+	if mask == nil {
+		var fields models.UserFields
+		if req.GetUsername() != "" {
+			fields = append(fields, models.UserFieldUsername)
+		}
+		if req.GetEmail() != "" {
+			fields = append(fields, models.UserFieldEmail)
+		}
+		if req.FullName != nil {
+			fields = append(fields, models.UserFieldFullName)
+		}
+		if req.Bio != nil {
+			fields = append(fields, models.UserFieldBio)
+		}
+		return fields, nil
+	}
+
+	fields := make(models.UserFields, 0, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		field, ok := maskFields[path]
+		if !ok {
+			return nil, fmt.Errorf("field %q cannot be updated", path)
+		}
+		fields = append(fields, field)
+	}
+	if fields.Has(models.UserFieldUsername) && req.GetUsername() == "" {
+		return nil, errors.New("username cannot be cleared")
+	}
+	if fields.Has(models.UserFieldEmail) && req.GetEmail() == "" {
+		return nil, errors.New("email cannot be cleared")
+	}
+	return fields, nil
+}
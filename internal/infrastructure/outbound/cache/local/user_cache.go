@@ -0,0 +1,365 @@
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash/maphash"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"pinstack-user-service/internal/domain/models"
+	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/domain/ports/output/cache"
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+)
+
+const (
+	localTier  = "local"
+	remoteTier = "redis"
+
+	emailPointerPrefix    = "email:"
+	usernamePointerPrefix = "username:"
+
+	// evictionSlots is how many slots evictions are tracked in; keys that
+	// share a slot also share their eviction history.
+	evictionSlots = 1024
+)
+
+// invalidation names the entries to drop. Origin identifies the publishing
+// instance, which has already applied the invalidation locally.
+type invalidation struct {
+	Origin   string `json:"origin"`
+	UserID   int64  `json:"user_id,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// UserCache is an in-process LRU in front of another cache.UserCache
+// (normally Redis). Users are stored once by id and email/username lookups go
+// through pointers to that id, mirroring the Redis layout. Only reads populate
+// it, always with what the remote tier returned, so versioning stays the
+// remote tier's job; writes pass through and drop the affected entries here
+// and, via the invalidator, on every other instance, while read fills
+// (FillUser) pass through without invalidating anything. Not-found results
+// are not kept locally.
+type UserCache struct {
+	next        cache.UserCache
+	invalidator cache.Invalidator
+	origin      string
+
+	// mu makes "check evictions, then store" atomic with respect to
+	// evictions, so a read that started before an invalidation cannot put
+	// the entry it loaded back afterwards. Every eviction takes the next seq;
+	// evicted[slot] holds the seq of the last eviction of a key in slot and
+	// purged that of the last Purge, so only reads of the evicted keys are
+	// dropped.
+	mu       sync.Mutex
+	seq      atomic.Uint64
+	evicted  [evictionSlots]uint64
+	purged   uint64
+	seed     maphash.Seed
+	users    *expirable.LRU[int64, *models.User]
+	pointers *expirable.LRU[string, int64]
+
+	log     ports.Logger
+	metrics ports.MetricsProvider
+}
+
+func NewUserCache(next cache.UserCache, invalidator cache.Invalidator, cfg config.LocalCache, log ports.Logger, metrics ports.MetricsProvider) *UserCache {
+	return &UserCache{
+		next:        next,
+		invalidator: invalidator,
+		origin:      newOrigin(),
+		seed:        maphash.MakeSeed(),
+		users:       expirable.NewLRU[int64, *models.User](cfg.Size, nil, cfg.TTL),
+		pointers:    expirable.NewLRU[string, int64](cfg.Size, nil, cfg.TTL),
+		log:         log,
+		metrics:     metrics,
+	}
+}
+
+// Start subscribes to invalidations published by other instances until ctx
+// is done.
+func (c *UserCache) Start(ctx context.Context) error {
+	return c.invalidator.Subscribe(ctx, c.handleInvalidation)
+}
+
+func (c *UserCache) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	if user, ok := c.users.Get(userID); ok {
+		c.metrics.IncrementCacheTierHits(localTier)
+		c.log.Debug("Local user cache hit", slog.Int64("user_id", userID))
+		return copyUser(user), nil
+	}
+	c.metrics.IncrementCacheTierMisses(localTier)
+
+	since := c.seq.Load()
+	user, err := c.next.GetUserByID(ctx, userID)
+	c.recordRemote(err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(since, user)
+	return user, nil
+}
+
+func (c *UserCache) GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.User, error) {
+	users := make(map[int64]*models.User, len(userIDs))
+	missing := make([]int64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if user, ok := c.users.Get(id); ok {
+			c.metrics.IncrementCacheTierHits(localTier)
+			users[id] = copyUser(user)
+			continue
+		}
+		c.metrics.IncrementCacheTierMisses(localTier)
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	since := c.seq.Load()
+	fetched, err := c.next.GetUsersByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range missing {
+		user, ok := fetched[id]
+		if !ok {
+			c.metrics.IncrementCacheTierMisses(remoteTier)
+			continue
+		}
+		c.metrics.IncrementCacheTierHits(remoteTier)
+		c.store(since, user)
+		users[id] = user
+	}
+	return users, nil
+}
+
+func (c *UserCache) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return c.getByPointer(ctx, emailPointerPrefix+email, slog.String("email", email),
+		func(user *models.User) bool { return user.Email == email },
+		func(ctx context.Context) (*models.User, error) { return c.next.GetUserByEmail(ctx, email) })
+}
+
+func (c *UserCache) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return c.getByPointer(ctx, usernamePointerPrefix+username, slog.String("username", username),
+		func(user *models.User) bool { return user.Username == username },
+		func(ctx context.Context) (*models.User, error) { return c.next.GetUserByUsername(ctx, username) })
+}
+
+func (c *UserCache) getByPointer(
+	ctx context.Context,
+	key string,
+	attr slog.Attr,
+	matches func(*models.User) bool,
+	fetch func(ctx context.Context) (*models.User, error),
+) (*models.User, error) {
+	if id, ok := c.pointers.Get(key); ok {
+		if user, ok := c.users.Get(id); ok && matches(user) {
+			c.metrics.IncrementCacheTierHits(localTier)
+			c.log.Debug("Local user cache hit", attr)
+			return copyUser(user), nil
+		}
+	}
+	c.metrics.IncrementCacheTierMisses(localTier)
+
+	since := c.seq.Load()
+	user, err := fetch(ctx)
+	c.recordRemote(err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(since, user)
+	return user, nil
+}
+
+func (c *UserCache) SetUser(ctx context.Context, user *models.User) error {
+	err := c.next.SetUser(ctx, user)
+	if user != nil {
+		c.invalidate(ctx, invalidation{UserID: user.ID, Email: user.Email, Username: user.Username})
+	}
+	return err
+}
+
+// FillUser passes a user loaded by a read on to the next tier. Nothing changed,
+// so no instance has anything to drop.
+func (c *UserCache) FillUser(ctx context.Context, user *models.User) error {
+	return c.next.FillUser(ctx, user)
+}
+
+func (c *UserCache) SetUserNotFoundByID(ctx context.Context, userID int64, version int64) error {
+	err := c.next.SetUserNotFoundByID(ctx, userID, version)
+	c.invalidate(ctx, invalidation{UserID: userID})
+	return err
+}
+
+func (c *UserCache) ReleaseUsername(ctx context.Context, username string) error {
+	err := c.next.ReleaseUsername(ctx, username)
+	c.invalidate(ctx, invalidation{Username: username})
+	return err
+}
+
+func (c *UserCache) ReleaseEmail(ctx context.Context, email string) error {
+	err := c.next.ReleaseEmail(ctx, email)
+	c.invalidate(ctx, invalidation{Email: email})
+	return err
+}
+
+// The FillUserNotFound methods pass reads that found nothing on to the next
+// tier. Not-found results are never kept locally, so there is nothing to drop.
+func (c *UserCache) FillUserNotFoundByID(ctx context.Context, userID int64) error {
+	return c.next.FillUserNotFoundByID(ctx, userID)
+}
+
+func (c *UserCache) FillUserNotFoundByUsername(ctx context.Context, username string) error {
+	return c.next.FillUserNotFoundByUsername(ctx, username)
+}
+
+func (c *UserCache) FillUserNotFoundByEmail(ctx context.Context, email string) error {
+	return c.next.FillUserNotFoundByEmail(ctx, email)
+}
+
+func (c *UserCache) DeleteUser(ctx context.Context, user *models.User) error {
+	err := c.next.DeleteUser(ctx, user)
+	if user != nil {
+		c.invalidate(ctx, invalidation{UserID: user.ID, Email: user.Email, Username: user.Username})
+	}
+	return err
+}
+
+func (c *UserCache) DeleteUserByID(ctx context.Context, userID int64) error {
+	err := c.next.DeleteUserByID(ctx, userID)
+	c.invalidate(ctx, invalidation{UserID: userID})
+	return err
+}
+
+// Purge drops every local entry. It is used when invalidations may have been
+// missed, e.g. after the connection to Redis comes back.
+func (c *UserCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purged = c.seq.Add(1)
+	c.users.Purge()
+	c.pointers.Purge()
+	c.log.Info("Local user cache purged")
+}
+
+// store keeps a copy of user unless one of its keys was evicted since the
+// read that produced it started (since is the seq at that point), or a newer
+// version is already cached.
+func (c *UserCache) store(since uint64, user *models.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.purged > since ||
+		c.evicted[c.slot(idKey(user.ID))] > since ||
+		c.evicted[c.slot(emailPointerPrefix+user.Email)] > since ||
+		c.evicted[c.slot(usernamePointerPrefix+user.Username)] > since {
+		return
+	}
+	if cached, ok := c.users.Peek(user.ID); ok && cached.Version > user.Version {
+		return
+	}
+
+	c.users.Add(user.ID, copyUser(user))
+	c.pointers.Add(emailPointerPrefix+user.Email, user.ID)
+	c.pointers.Add(usernamePointerPrefix+user.Username, user.ID)
+}
+
+// invalidate drops the entries locally and asks every other instance to do
+// the same. A failed publish is only logged: the write itself already went
+// through, and the local TTL bounds how long other instances stay stale.
+func (c *UserCache) invalidate(ctx context.Context, msg invalidation) {
+	c.evict(msg)
+
+	msg.Origin = c.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.log.Warn("Failed to encode cache invalidation", slog.String("error", err.Error()))
+		return
+	}
+	if err := c.invalidator.Publish(ctx, data); err != nil {
+		c.log.Warn("Failed to publish cache invalidation",
+			slog.Int64("user_id", msg.UserID),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (c *UserCache) handleInvalidation(message []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(message, &msg); err != nil {
+		c.log.Warn("Failed to decode cache invalidation", slog.String("error", err.Error()))
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+
+	c.evict(msg)
+	c.log.Debug("Applied cache invalidation",
+		slog.String("origin", msg.Origin),
+		slog.Int64("user_id", msg.UserID))
+}
+
+func (c *UserCache) evict(msg invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seq := c.seq.Add(1)
+	if msg.UserID != 0 {
+		c.users.Remove(msg.UserID)
+		c.evicted[c.slot(idKey(msg.UserID))] = seq
+	}
+	if msg.Email != "" {
+		c.pointers.Remove(emailPointerPrefix + msg.Email)
+		c.evicted[c.slot(emailPointerPrefix+msg.Email)] = seq
+	}
+	if msg.Username != "" {
+		c.pointers.Remove(usernamePointerPrefix + msg.Username)
+		c.evicted[c.slot(usernamePointerPrefix+msg.Username)] = seq
+	}
+}
+
+func (c *UserCache) slot(key string) int {
+	return int(maphash.String(c.seed, key) % evictionSlots)
+}
+
+func idKey(id int64) string {
+	return "id:" + strconv.FormatInt(id, 10)
+}
+
+// recordRemote counts a remote lookup: a cached user or not-found entry is a
+// hit, ErrCacheMiss is a miss, and failures are not counted.
+func (c *UserCache) recordRemote(err error) {
+	switch {
+	case err == nil, errors.Is(err, custom_errors.ErrUserNotFound):
+		c.metrics.IncrementCacheTierHits(remoteTier)
+	case errors.Is(err, custom_errors.ErrCacheMiss):
+		c.metrics.IncrementCacheTierMisses(remoteTier)
+	}
+}
+
+func copyUser(user *models.User) *models.User {
+	u := *user
+	return &u
+}
+
+func newOrigin() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
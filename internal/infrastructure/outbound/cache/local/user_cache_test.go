@@ -0,0 +1,284 @@
+package local
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pinstack-user-service/internal/domain/models"
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteCache is a minimal remote tier keyed by id that counts reads.
+type remoteCache struct {
+	mu     sync.Mutex
+	users  map[int64]*models.User
+	reads  int
+	onRead func()
+}
+
+func newRemoteCache() *remoteCache {
+	return &remoteCache{users: make(map[int64]*models.User)}
+}
+
+func (r *remoteCache) lookup(match func(*models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	r.reads++
+	onRead := r.onRead
+	var found *models.User
+	for _, user := range r.users {
+		if match(user) {
+			u := *user
+			found = &u
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if onRead != nil {
+		onRead()
+	}
+	if found == nil {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	return found, nil
+}
+
+func (r *remoteCache) readCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads
+}
+
+func (r *remoteCache) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	return r.lookup(func(u *models.User) bool { return u.ID == userID })
+}
+
+func (r *remoteCache) GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.User, error) {
+	users := make(map[int64]*models.User)
+	for _, id := range userIDs {
+		if user, err := r.GetUserByID(ctx, id); err == nil {
+			users[id] = user
+		}
+	}
+	return users, nil
+}
+
+func (r *remoteCache) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.lookup(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *remoteCache) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.lookup(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *remoteCache) SetUser(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := *user
+	r.users[user.ID] = &u
+	return nil
+}
+
+func (r *remoteCache) FillUser(ctx context.Context, user *models.User) error {
+	return r.SetUser(ctx, user)
+}
+
+func (r *remoteCache) SetUserNotFoundByID(ctx context.Context, userID int64, version int64) error {
+	return r.DeleteUserByID(ctx, userID)
+}
+
+func (r *remoteCache) ReleaseUsername(ctx context.Context, username string) error {
+	return nil
+}
+
+func (r *remoteCache) ReleaseEmail(ctx context.Context, email string) error {
+	return nil
+}
+
+func (r *remoteCache) FillUserNotFoundByID(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (r *remoteCache) FillUserNotFoundByUsername(ctx context.Context, username string) error {
+	return nil
+}
+
+func (r *remoteCache) FillUserNotFoundByEmail(ctx context.Context, email string) error {
+	return nil
+}
+
+func (r *remoteCache) DeleteUser(ctx context.Context, user *models.User) error {
+	return r.DeleteUserByID(ctx, user.ID)
+}
+
+func (r *remoteCache) DeleteUserByID(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, userID)
+	return nil
+}
+
+// bus delivers every published message to all subscribers synchronously.
+type bus struct {
+	mu        sync.Mutex
+	handlers  []func(message []byte)
+	published int
+}
+
+func (b *bus) Publish(ctx context.Context, message []byte) error {
+	b.mu.Lock()
+	b.published++
+	handlers := append([]func([]byte){}, b.handlers...)
+	b.mu.Unlock()
+	for _, handler := range handlers {
+		handler(message)
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(ctx context.Context, handler func(message []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+func newTestCache(t *testing.T, remote *remoteCache, invalidations *bus) *UserCache {
+	cfg := config.LocalCache{Enabled: true, Size: 100, TTL: time.Minute}
+	c := NewUserCache(remote, invalidations, cfg, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+	require.NoError(t, c.Start(context.Background()))
+	return c
+}
+
+func testUser(version int64) *models.User {
+	return &models.User{ID: 1, Username: "alice", Email: "alice@example.com", Version: version}
+}
+
+func TestUserCache_ServesRepeatReadsLocally(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteCache()
+	require.NoError(t, remote.SetUser(ctx, testUser(1)))
+	c := newTestCache(t, remote, &bus{})
+
+	for i := 0; i < 3; i++ {
+		user, err := c.GetUserByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+	}
+	user, err := c.GetUserByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user.ID)
+
+	assert.Equal(t, 1, remote.readCount())
+}
+
+func TestUserCache_InvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteCache()
+	invalidations := &bus{}
+	writer := newTestCache(t, remote, invalidations)
+	reader := newTestCache(t, remote, invalidations)
+
+	require.NoError(t, remote.SetUser(ctx, testUser(1)))
+	_, err := reader.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+
+	renamed := testUser(2)
+	renamed.Username = "alice2"
+	require.NoError(t, writer.SetUser(ctx, renamed))
+
+	user, err := reader.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice2", user.Username)
+
+	_, err = reader.GetUserByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+}
+
+func TestUserCache_DropsReadRacingInvalidation(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteCache()
+	require.NoError(t, remote.SetUser(ctx, testUser(1)))
+	c := newTestCache(t, remote, &bus{})
+
+	// The user is deleted while the first read is in flight; the value that
+	// read returns must not be kept locally.
+	var once sync.Once
+	remote.onRead = func() {
+		once.Do(func() {
+			c.evict(invalidation{UserID: 1})
+		})
+	}
+
+	_, err := c.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = c.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, remote.readCount())
+}
+
+func TestUserCache_FillDoesNotInvalidate(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteCache()
+	invalidations := &bus{}
+	filler := newTestCache(t, remote, invalidations)
+	reader := newTestCache(t, remote, invalidations)
+
+	require.NoError(t, remote.SetUser(ctx, testUser(1)))
+	_, err := reader.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+
+	other := &models.User{ID: 2, Username: "bob", Email: "bob@example.com", Version: 1}
+	require.NoError(t, filler.FillUser(ctx, other))
+
+	assert.Zero(t, invalidations.published)
+	_, err = reader.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, remote.readCount(), "the fill must not drop other instances' entries")
+
+	user, err := reader.GetUserByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", user.Username)
+}
+
+func TestUserCache_KeepsReadRacingInvalidationOfAnotherUser(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteCache()
+	require.NoError(t, remote.SetUser(ctx, testUser(1)))
+	c := newTestCache(t, remote, &bus{})
+
+	// Pick a user whose id lands in none of alice's eviction slots.
+	alice := testUser(1)
+	aliceSlots := map[int]bool{
+		c.slot(idKey(alice.ID)):                        true,
+		c.slot(emailPointerPrefix + alice.Email):       true,
+		c.slot(usernamePointerPrefix + alice.Username): true,
+	}
+	otherID := int64(2)
+	for aliceSlots[c.slot(idKey(otherID))] {
+		otherID++
+	}
+
+	var once sync.Once
+	remote.onRead = func() {
+		once.Do(func() {
+			c.evict(invalidation{UserID: otherID})
+		})
+	}
+
+	_, err := c.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = c.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, remote.readCount())
+}
@@ -0,0 +1,172 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+)
+
+// The access count keys share the {user_access} hash tag so that they can be
+// summed in one command in cluster mode.
+const (
+	accessCountsKeyPrefix = "{user_access}:counts:"
+	accessUnionKey        = "{user_access}:union"
+)
+
+// accessShards spreads the buffered counts over independently locked maps so
+// that concurrent reads rarely wait on each other to record an access.
+const accessShards = 64
+
+type accessShard struct {
+	mu     sync.Mutex
+	counts map[int64]float64
+}
+
+// AccessTracker counts reads in memory and adds them to a sorted set of
+// access counts every flush interval, so recording an access never waits on
+// Redis. There is one set per window, trimmed to the most read maxTracked
+// users on every flush and expiring once it falls out of the last windows
+// windows. MostAccessed ranks users by their reads over those windows, so
+// users that were popular long ago drop out.
+type AccessTracker struct {
+	client        *Client
+	maxTracked    int64
+	flushInterval time.Duration
+	window        time.Duration
+	windows       int
+	log           ports.Logger
+	now           func() time.Time
+
+	shards [accessShards]accessShard
+}
+
+func NewAccessTracker(client *Client, cfg config.Warmup, log ports.Logger) *AccessTracker {
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+	window := cfg.AccessWindow
+	if window <= 0 {
+		window = time.Hour
+	}
+	windows := cfg.AccessWindows
+	if windows <= 0 {
+		windows = 1
+	}
+
+	t := &AccessTracker{
+		client:        client,
+		maxTracked:    int64(cfg.TrackedUsers),
+		flushInterval: flushInterval,
+		window:        window,
+		windows:       windows,
+		log:           log,
+		now:           time.Now,
+	}
+	for i := range t.shards {
+		t.shards[i].counts = make(map[int64]float64)
+	}
+	return t
+}
+
+// Start flushes recorded accesses every flush interval until ctx is done,
+// then flushes once more.
+func (t *AccessTracker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(t.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), t.flushInterval)
+				t.flush(flushCtx)
+				cancel()
+				return
+			case <-ticker.C:
+				t.flush(ctx)
+			}
+		}
+	}()
+}
+
+func (t *AccessTracker) RecordAccess(userIDs ...int64) {
+	for _, id := range userIDs {
+		shard := &t.shards[uint64(id)%accessShards]
+		shard.mu.Lock()
+		shard.counts[id]++
+		shard.mu.Unlock()
+	}
+}
+
+func (t *AccessTracker) MostAccessed(ctx context.Context, limit int) ([]int64, error) {
+	now := t.now()
+	keys := make([]string, 0, t.windows)
+	for i := range t.windows {
+		keys = append(keys, t.windowKey(now.Add(-time.Duration(i)*t.window)))
+	}
+
+	members, err := t.client.ZUnionRevRange(ctx, keys, accessUnionKey, limit)
+	if errors.Is(err, ErrUnavailable) {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most accessed users: %w", err)
+	}
+
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// windowKey is the key of the window that contains at, named after the
+// window's start.
+func (t *AccessTracker) windowKey(at time.Time) string {
+	start := at.Truncate(t.window)
+	return accessCountsKeyPrefix + strconv.FormatInt(start.Unix(), 10)
+}
+
+// flush hands the buffered counts to Redis. Counts are dropped if that fails;
+// they only steer warm-up, so keeping them is not worth unbounded memory.
+func (t *AccessTracker) flush(ctx context.Context) {
+	increments := make(map[string]float64)
+	for i := range t.shards {
+		shard := &t.shards[i]
+		shard.mu.Lock()
+		counts := shard.counts
+		shard.counts = make(map[int64]float64, len(counts))
+		shard.mu.Unlock()
+
+		for id, count := range counts {
+			increments[strconv.FormatInt(id, 10)] = count
+		}
+	}
+
+	if len(increments) == 0 {
+		return
+	}
+
+	// A window's set is read for windows windows after it starts.
+	ttl := time.Duration(t.windows) * t.window
+	err := t.client.ZIncrBy(ctx, t.windowKey(t.now()), increments, t.maxTracked, ttl)
+	if err != nil && !errors.Is(err, ErrUnavailable) {
+		t.log.Warn("Failed to record user accesses",
+			slog.Int("users", len(increments)),
+			slog.String("error", err.Error()))
+	}
+}
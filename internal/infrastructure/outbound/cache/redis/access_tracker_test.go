@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTracker_MostAccessed(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	tracker := NewAccessTracker(client, config.Warmup{
+		TrackedUsers:  10,
+		AccessWindow:  time.Hour,
+		AccessWindows: 2,
+	}, logger.New("test"))
+	now := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.RecordAccess(1, 1, 2)
+		}()
+	}
+	tracker.RecordAccess(3)
+	wg.Wait()
+	tracker.flush(ctx)
+
+	ids, err := tracker.MostAccessed(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids)
+
+	key := tracker.windowKey(now)
+	assert.Equal(t, 2*time.Hour, server.TTL(key), "a window expires once it is no longer read")
+
+	t.Run("counts add up across windows", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		tracker.RecordAccess(3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3)
+		tracker.flush(ctx)
+
+		ids, err := tracker.MostAccessed(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 1, 2}, ids)
+	})
+
+	t.Run("old windows stop counting", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		tracker.RecordAccess(2)
+		tracker.flush(ctx)
+
+		ids, err := tracker.MostAccessed(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2}, ids)
+	})
+
+	assert.False(t, server.Exists(accessUnionKey), "the union is not left behind")
+}
@@ -0,0 +1,256 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable is returned without contacting Redis while the circuit
+// breaker is open.
+var ErrUnavailable = errors.New("redis is unavailable")
+
+// maxPendingKeys caps how many keys the breaker remembers to delete on
+// recovery. Past that, stale entries may be served until they expire.
+const maxPendingKeys = 10000
+
+// breaker trips after threshold consecutive connectivity failures. While it
+// is open calls fail fast with ErrUnavailable and a background probe pings
+// Redis every probeInterval; the first successful ping closes it again.
+//
+// Writes that fail or are skipped while Redis is unreachable leave whatever
+// the keys held before in place, so their keys are remembered and deleted
+// before the breaker closes. That way a user updated during an outage is not
+// served from its pre-outage entry afterwards.
+type breaker struct {
+	threshold     int
+	probeInterval time.Duration
+	ping          func(ctx context.Context) error
+	purge         func(ctx context.Context, keys []string) error
+	log           ports.Logger
+
+	mu              sync.Mutex
+	failures        int
+	open            bool
+	pending         map[string]struct{}
+	pendingOverflow bool
+	flushing        bool
+	listeners       []func(available bool)
+	closed          chan struct{}
+}
+
+func newBreaker(
+	threshold int,
+	probeInterval time.Duration,
+	ping func(ctx context.Context) error,
+	purge func(ctx context.Context, keys []string) error,
+	log ports.Logger,
+) *breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if probeInterval <= 0 {
+		probeInterval = time.Second
+	}
+	return &breaker{
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		ping:          ping,
+		purge:         purge,
+		log:           log,
+		pending:       make(map[string]struct{}),
+		closed:        make(chan struct{}),
+	}
+}
+
+// allow reports ErrUnavailable while the breaker is open. writeKeys are the
+// keys the caller is about to modify; a skipped write is remembered like a
+// failed one.
+func (b *breaker) allow(writeKeys ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	b.addPendingLocked(writeKeys)
+	return ErrUnavailable
+}
+
+// record feeds the outcome of a call into the breaker.
+func (b *breaker) record(ctx context.Context, err error, writeKeys ...string) {
+	if !isConnectivityError(ctx, err) {
+		if err == nil || errors.Is(err, redis.Nil) {
+			b.mu.Lock()
+			b.failures = 0
+			// Writes that failed without tripping the breaker are cleaned
+			// up as soon as Redis answers again.
+			flush := !b.open && !b.flushing && len(b.pending) > 0
+			b.flushing = b.flushing || flush
+			b.mu.Unlock()
+
+			if flush {
+				go b.flushInBackground()
+			}
+		}
+		return
+	}
+
+	b.mu.Lock()
+	b.addPendingLocked(writeKeys)
+	b.failures++
+	trip := !b.open && b.failures >= b.threshold
+	b.mu.Unlock()
+
+	if trip {
+		b.trip(err)
+	}
+}
+
+// trip opens the breaker and starts probing in the background.
+func (b *breaker) trip(cause error) {
+	b.mu.Lock()
+	if b.open {
+		b.mu.Unlock()
+		return
+	}
+	b.open = true
+	listeners := b.listeners
+	b.mu.Unlock()
+
+	b.log.Warn("Redis circuit breaker opened, serving without cache",
+		slog.String("error", cause.Error()),
+		slog.Duration("probe_interval", b.probeInterval))
+	for _, listener := range listeners {
+		listener(false)
+	}
+
+	go b.probe()
+}
+
+func (b *breaker) probe() {
+	ticker := time.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.closed:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.probeInterval)
+		err := b.ping(ctx)
+		if err == nil {
+			err = b.flushPending(ctx)
+		}
+		cancel()
+
+		if err != nil {
+			b.log.Debug("Redis probe failed", slog.String("error", err.Error()))
+			continue
+		}
+
+		b.mu.Lock()
+		b.open = false
+		b.failures = 0
+		listeners := b.listeners
+		b.mu.Unlock()
+
+		b.log.Info("Redis circuit breaker closed, cache is available again")
+		for _, listener := range listeners {
+			listener(true)
+		}
+		return
+	}
+}
+
+// flushPending deletes every key whose write was lost while Redis was
+// unreachable. Keys stay pending if the delete fails.
+func (b *breaker) flushPending(ctx context.Context) error {
+	b.mu.Lock()
+	keys := make([]string, 0, len(b.pending))
+	for key := range b.pending {
+		keys = append(keys, key)
+	}
+	overflow := b.pendingOverflow
+	b.mu.Unlock()
+
+	if len(keys) > 0 {
+		if err := b.purge(ctx, keys); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	for _, key := range keys {
+		delete(b.pending, key)
+	}
+	b.pendingOverflow = false
+	b.mu.Unlock()
+
+	if overflow {
+		b.log.Warn("Too many cache writes were lost during the Redis outage, some entries may be stale until they expire",
+			slog.Int("max_pending_keys", maxPendingKeys))
+	}
+	return nil
+}
+
+func (b *breaker) flushInBackground() {
+	ctx, cancel := context.WithTimeout(context.Background(), b.probeInterval)
+	defer cancel()
+
+	if err := b.flushPending(ctx); err != nil {
+		b.log.Warn("Failed to delete cache keys left stale by failed writes", slog.String("error", err.Error()))
+	}
+
+	b.mu.Lock()
+	b.flushing = false
+	b.mu.Unlock()
+}
+
+func (b *breaker) addPendingLocked(keys []string) {
+	for _, key := range keys {
+		if len(b.pending) >= maxPendingKeys {
+			b.pendingOverflow = true
+			return
+		}
+		b.pending[key] = struct{}{}
+	}
+}
+
+func (b *breaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open
+}
+
+func (b *breaker) onChange(listener func(available bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+func (b *breaker) stop() {
+	close(b.closed)
+}
+
+// isConnectivityError tells failures that say something about Redis health
+// apart from cache misses, error replies from the server and calls the
+// caller itself gave up on.
+func isConnectivityError(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pinstack-user-service/internal/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnRefused = errors.New("dial tcp: connection refused")
+
+type probeTarget struct {
+	mu      sync.Mutex
+	up      bool
+	deleted []string
+}
+
+func (p *probeTarget) ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.up {
+		return errConnRefused
+	}
+	return nil
+}
+
+func (p *probeTarget) purge(ctx context.Context, keys []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deleted = append(p.deleted, keys...)
+	return nil
+}
+
+func (p *probeTarget) setUp(up bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.up = up
+}
+
+func (p *probeTarget) deletedKeys() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.deleted...)
+}
+
+func TestBreaker_TripsAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	target := &probeTarget{}
+	b := newBreaker(3, time.Hour, target.ping, target.purge, logger.New("test"))
+	defer b.stop()
+
+	b.record(ctx, errConnRefused)
+	b.record(ctx, errConnRefused)
+	assert.NoError(t, b.allow())
+
+	b.record(ctx, errConnRefused)
+	assert.ErrorIs(t, b.allow(), ErrUnavailable)
+	assert.False(t, b.available())
+}
+
+func TestBreaker_IgnoresNonConnectivityErrors(t *testing.T) {
+	target := &probeTarget{}
+	b := newBreaker(1, time.Hour, target.ping, target.purge, logger.New("test"))
+	defer b.stop()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.record(canceled, context.Canceled)
+
+	assert.NoError(t, b.allow())
+}
+
+func TestBreaker_RecoversAndDeletesSkippedWrites(t *testing.T) {
+	ctx := context.Background()
+	target := &probeTarget{}
+	b := newBreaker(1, 10*time.Millisecond, target.ping, target.purge, logger.New("test"))
+	defer b.stop()
+
+	changes := make(chan bool, 2)
+	b.onChange(func(available bool) { changes <- available })
+
+	b.record(ctx, errConnRefused, "user:1")
+	require.False(t, <-changes)
+	assert.ErrorIs(t, b.allow("user:2"), ErrUnavailable)
+
+	target.setUp(true)
+	select {
+	case available := <-changes:
+		assert.True(t, available)
+	case <-time.After(time.Second):
+		t.Fatal("breaker did not close after Redis came back")
+	}
+
+	assert.NoError(t, b.allow())
+	assert.ElementsMatch(t, []string{"user:1", "user:2"}, target.deletedKeys())
+}
@@ -2,10 +2,11 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"sync"
 	"time"
 
 	ports "pinstack-user-service/internal/domain/ports/output"
@@ -16,39 +17,83 @@ import (
 )
 
 type Client struct {
-	client *redis.Client
-	log    ports.Logger
+	client redis.UniversalClient
+	// cluster is set when keys may live on different nodes, so multi-key
+	// commands have to be split into one command per key.
+	cluster bool
+	breaker *breaker
+	encoder encoder
+	log     ports.Logger
 }
 
+// NewClient returns a client even when Redis cannot be reached: it then
+// starts with the circuit breaker open and connects once a background probe
+// succeeds, so callers run without a cache in the meantime.
 func NewClient(cfg config.Redis, log ports.Logger) (*Client, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Address, cfg.Port),
-		Password: cfg.Password,
-		DB:       cfg.DB,
-		PoolSize: cfg.PoolSize,
-	})
+	rdb, err := newUniversalClient(cfg)
+	if err != nil {
+		log.Error("Invalid Redis configuration", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid redis configuration: %w", err)
+	}
+
+	codec, err := NewCodec(cfg.Codec)
+	if err != nil {
+		log.Error("Invalid Redis configuration", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("invalid redis configuration: %w", err)
+	}
+
+	if cfg.Mode == ModeCluster && cfg.DB != 0 {
+		log.Warn("Redis cluster has no databases, ignoring db", slog.Int("db", cfg.DB))
+	}
+
+	c := &Client{
+		client:  rdb,
+		cluster: cfg.Mode == ModeCluster,
+		encoder: encoder{codec: codec, compressAbove: cfg.CompressAbove},
+		log:     log,
+	}
+	c.breaker = newBreaker(cfg.BreakerThreshold, cfg.BreakerProbeInterval, c.Ping, c.deleteBatches, log)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Error("Failed to connect to Redis", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		log.Warn("Failed to connect to Redis, starting without cache",
+			slog.String("mode", cfg.Mode),
+			slog.Any("addresses", addresses(cfg)),
+			slog.String("error", err.Error()))
+		c.breaker.trip(err)
+		return c, nil
 	}
 
 	log.Info("Successfully connected to Redis",
-		slog.String("address", cfg.Address),
-		slog.Int("port", cfg.Port),
-		slog.Int("db", cfg.DB))
+		slog.String("mode", cfg.Mode),
+		slog.Any("addresses", addresses(cfg)),
+		slog.Int("db", cfg.DB),
+		slog.Bool("tls", cfg.TLS.Enabled),
+		slog.String("codec", cfg.Codec))
 
-	return &Client{
-		client: rdb,
-		log:    log,
-	}, nil
+	return c, nil
+}
+
+// Available reports whether the circuit breaker lets calls through to Redis.
+func (c *Client) Available() bool {
+	return c.breaker.available()
+}
+
+// OnAvailabilityChange registers fn to be called whenever the circuit breaker
+// opens (false) or closes again (true).
+func (c *Client) OnAvailabilityChange(fn func(available bool)) {
+	c.breaker.onChange(fn)
 }
 
 func (c *Client) Get(ctx context.Context, key string, dest interface{}) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
 	val, err := c.client.Get(ctx, key).Result()
+	c.breaker.record(ctx, err)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			c.log.Debug("Cache miss", slog.String("key", key))
@@ -60,27 +105,111 @@ func (c *Client) Get(ctx context.Context, key string, dest interface{}) error {
 		return fmt.Errorf("failed to get from cache: %w", err)
 	}
 
-	if err := json.Unmarshal([]byte(val), dest); err != nil {
-		c.log.Error("Failed to unmarshal cache value",
+	if err := c.decode(key, val, dest); err != nil {
+		return err
+	}
+
+	c.log.Debug("Cache hit", slog.String("key", key))
+	return nil
+}
+
+// getAndSlideScript returns the value at KEYS[1] and, if its remaining TTL is
+// above ARGV[2] and below ARGV[3] milliseconds, resets the TTL to ARGV[1].
+var getAndSlideScript = redis.NewScript(`
+local val = redis.call('GET', KEYS[1])
+if val then
+    local ttl = redis.call('PTTL', KEYS[1])
+    if ttl > tonumber(ARGV[2]) and ttl < tonumber(ARGV[3]) then
+        redis.call('PEXPIRE', KEYS[1], ARGV[1])
+    end
+end
+return val
+`)
+
+// GetAndSlide reads key like Get and, in the same script, resets its TTL to
+// ttl if the remaining TTL lies strictly between above and below. Keys
+// outside that range keep their TTL.
+func (c *Client) GetAndSlide(ctx context.Context, key string, dest interface{}, ttl, above, below time.Duration) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	val, err := getAndSlideScript.Run(ctx, c.client, []string{key},
+		ttl.Milliseconds(), above.Milliseconds(), below.Milliseconds()).Text()
+	c.breaker.record(ctx, err)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			c.log.Debug("Cache miss", slog.String("key", key))
+			return custom_errors.ErrCacheMiss
+		}
+		c.log.Error("Failed to get from cache",
 			slog.String("key", key),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to unmarshal cache value: %w", err)
+		return fmt.Errorf("failed to get from cache: %w", err)
+	}
+
+	if err := c.decode(key, val, dest); err != nil {
+		return err
 	}
 
 	c.log.Debug("Cache hit", slog.String("key", key))
 	return nil
 }
 
+// MGet fetches keys in one round trip and decodes every hit into the value
+// returned by dest for its index. Missing keys are skipped, and so are
+// entries that fail to decode, which are logged and treated as misses.
+func (c *Client) MGet(ctx context.Context, keys []string, dest func(i int) interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	vals, err := c.mget(ctx, keys)
+	c.breaker.record(ctx, err)
+	if err != nil {
+		c.log.Error("Failed to get multiple keys from cache",
+			slog.Int("count", len(keys)),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to get multiple keys from cache: %w", err)
+	}
+
+	for i, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			continue
+		}
+		_, payload := splitVersion(str)
+		if err := decode([]byte(payload), dest(i)); err != nil {
+			c.log.Warn("Failed to decode cache value, treating as miss",
+				slog.String("key", keys[i]),
+				slog.String("error", err.Error()))
+			continue
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
-	data, err := json.Marshal(value)
+	data, err := c.encoder.encode(value)
 	if err != nil {
-		c.log.Error("Failed to marshal value for cache",
+		c.log.Error("Failed to encode value for cache",
 			slog.String("key", key),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to marshal value: %w", err)
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	if err := c.breaker.allow(key); err != nil {
+		return err
 	}
 
-	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	err = c.client.Set(ctx, key, data, ttl).Err()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
 		c.log.Error("Failed to set cache",
 			slog.String("key", key),
 			slog.String("error", err.Error()))
@@ -93,25 +222,597 @@ func (c *Client) Set(ctx context.Context, key string, value interface{}, ttl tim
 	return nil
 }
 
-func (c *Client) Delete(ctx context.Context, key string) error {
-	result, err := c.client.Del(ctx, key).Result()
+// Versioned values are stored as a zero-padded decimal version followed by
+// the encoded payload, so the Lua script can order them with a plain string
+// comparison. Get and MGet strip the prefix transparently.
+const versionPrefixLength = 20
+
+// setIfNotOlderScript writes ARGV[i+2] under KEYS[i] for every key that does
+// not already hold a value with a higher version than ARGV[1], expiring each
+// after ARGV[2] milliseconds. Values without a version prefix are always
+// replaced. The script runs atomically, so either all eligible keys are
+// written or, on error, none are.
+var setIfNotOlderScript = redis.NewScript(`
+local stored = 0
+for i, key in ipairs(KEYS) do
+    local current = redis.call('GET', key)
+    local version = current and string.match(current, '^' .. string.rep('%d', 20))
+    if not (version and version > ARGV[1]) then
+        redis.call('SET', key, ARGV[1] .. ARGV[i + 2], 'PX', ARGV[2])
+        stored = stored + 1
+    end
+end
+return stored
+`)
+
+// SetVersioned stores value under key unless a newer version is already
+// cached, and reports whether the write happened.
+func (c *Client) SetVersioned(ctx context.Context, key string, version int64, value interface{}, ttl time.Duration) (bool, error) {
+	stored, err := c.SetVersionedMulti(ctx, []string{key}, []interface{}{value}, version, ttl)
+	return stored == 1, err
+}
+
+// SetVersionedMulti stores values[i] under keys[i] with a shared version and
+// TTL in a single atomic script, skipping keys that already hold a newer
+// version. It returns how many keys were written.
+func (c *Client) SetVersionedMulti(ctx context.Context, keys []string, values []interface{}, version int64, ttl time.Duration) (int, error) {
+	if len(keys) != len(values) {
+		return 0, fmt.Errorf("got %d keys but %d values", len(keys), len(values))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	args := make([]interface{}, 0, len(values)+2)
+	args = append(args, formatVersion(version), ttl.Milliseconds())
+	for i, value := range values {
+		data, err := c.encoder.encode(value)
+		if err != nil {
+			c.log.Error("Failed to encode value for cache",
+				slog.String("key", keys[i]),
+				slog.String("error", err.Error()))
+			return 0, fmt.Errorf("failed to encode value: %w", err)
+		}
+		args = append(args, data)
+	}
+
+	if err := c.breaker.allow(keys...); err != nil {
+		return 0, err
+	}
+
+	stored, err := c.setIfNotOlder(ctx, keys, args)
+	c.breaker.record(ctx, err, keys...)
 	if err != nil {
-		c.log.Error("Failed to delete from cache",
+		c.log.Error("Failed to set versioned cache",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()))
+		return 0, fmt.Errorf("failed to set versioned cache: %w", err)
+	}
+
+	c.log.Debug("Set versioned cache",
+		slog.Any("keys", keys),
+		slog.Int64("version", version),
+		slog.Int("stored", stored),
+		slog.Duration("ttl", ttl))
+	return stored, nil
+}
+
+// SetVersionedIfAbsent stores value under key with the given version only if
+// the key holds nothing, and reports whether the write happened.
+func (c *Client) SetVersionedIfAbsent(ctx context.Context, key string, version int64, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := c.encoder.encode(value)
+	if err != nil {
+		c.log.Error("Failed to encode value for cache",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return false, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	if err := c.breaker.allow(key); err != nil {
+		return false, err
+	}
+
+	stored, err := c.client.SetNX(ctx, key, formatVersion(version)+string(data), ttl).Result()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to set cache if absent",
 			slog.String("key", key),
 			slog.String("error", err.Error()))
+		return false, fmt.Errorf("failed to set cache if absent: %w", err)
+	}
+
+	c.log.Debug("Set cache if absent",
+		slog.String("key", key),
+		slog.Bool("stored", stored),
+		slog.Duration("ttl", ttl))
+	return stored, nil
+}
+
+// ReplaceVersioned stores value with the given version under every key,
+// replacing whatever the keys hold, newer versions included. The keys are
+// written in one pipeline but not atomically.
+func (c *Client) ReplaceVersioned(ctx context.Context, keys []string, version int64, value interface{}, ttl time.Duration) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	data, err := c.encoder.encode(value)
+	if err != nil {
+		c.log.Error("Failed to encode value for cache",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	if err := c.breaker.allow(keys...); err != nil {
+		return err
+	}
+
+	versioned := formatVersion(version) + string(data)
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Set(ctx, key, versioned, ttl)
+		}
+		return nil
+	})
+	c.breaker.record(ctx, err, keys...)
+	if err != nil {
+		c.log.Error("Failed to replace versioned cache",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to replace versioned cache: %w", err)
+	}
+
+	c.log.Debug("Replaced versioned cache",
+		slog.Any("keys", keys),
+		slog.Int64("version", version),
+		slog.Duration("ttl", ttl))
+	return nil
+}
+
+// decode strips the version prefix from val and decodes the payload into
+// dest. Entries written in a format this build does not know, e.g. by a newer
+// release during a rollout, are reported as cache misses.
+func (c *Client) decode(key, val string, dest interface{}) error {
+	_, payload := splitVersion(val)
+	if err := decode([]byte(payload), dest); err != nil {
+		if errors.Is(err, errUnknownFormat) {
+			c.log.Debug("Cache entry has unknown format, treating as miss", slog.String("key", key))
+			return custom_errors.ErrCacheMiss
+		}
+		c.log.Error("Failed to decode cache value",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+	return nil
+}
+
+func formatVersion(version int64) string {
+	return fmt.Sprintf("%0*d", versionPrefixLength, version)
+}
+
+// splitVersion separates a versioned value into its version and payload.
+// Values written without a version report version 0.
+func splitVersion(val string) (int64, string) {
+	if len(val) < versionPrefixLength {
+		return 0, val
+	}
+	version, err := strconv.ParseInt(val[:versionPrefixLength], 10, 64)
+	if err != nil {
+		return 0, val
+	}
+	return version, val[versionPrefixLength:]
+}
+
+// Delete removes keys with a single DEL, which Redis applies atomically. In
+// cluster mode keys are deleted one by one since they may sit on different
+// nodes.
+func (c *Client) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.breaker.allow(keys...); err != nil {
+		return err
+	}
+
+	result, err := c.del(ctx, keys)
+	c.breaker.record(ctx, err, keys...)
+	if err != nil {
+		c.log.Error("Failed to delete from cache",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()))
 		return fmt.Errorf("failed to delete from cache: %w", err)
 	}
 
 	if result == 0 {
-		c.log.Debug("Key not found for deletion", slog.String("key", key))
+		c.log.Debug("Keys not found for deletion", slog.Any("keys", keys))
 	} else {
-		c.log.Debug("Successfully deleted from cache", slog.String("key", key))
+		c.log.Debug("Successfully deleted from cache",
+			slog.Any("keys", keys),
+			slog.Int64("deleted", result))
 	}
 
 	return nil
 }
 
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	err := c.client.PExpire(ctx, key, ttl).Err()
+	c.breaker.record(ctx, err)
+	if err != nil {
+		c.log.Error("Failed to set cache expiry",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to set cache expiry: %w", err)
+	}
+	return nil
+}
+
+// incrFromScript increments a counter, first setting it to ARGV[1] if it
+// does not exist.
+var incrFromScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    redis.call('SET', KEYS[1], ARGV[1])
+end
+return redis.call('INCR', KEYS[1])
+`)
+
+// IncrFrom increments the counter under key and returns its new value. A
+// missing counter starts from initial.
+func (c *Client) IncrFrom(ctx context.Context, key string, initial int64) (int64, error) {
+	if err := c.breaker.allow(key); err != nil {
+		return 0, err
+	}
+
+	val, err := incrFromScript.Run(ctx, c.client, []string{key}, initial).Int64()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to increment cache counter",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return 0, fmt.Errorf("failed to increment cache counter: %w", err)
+	}
+	return val, nil
+}
+
+func (c *Client) GetRaw(ctx context.Context, key string) (string, error) {
+	if err := c.breaker.allow(); err != nil {
+		return "", err
+	}
+
+	val, err := c.client.Get(ctx, key).Result()
+	c.breaker.record(ctx, err)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", custom_errors.ErrCacheMiss
+		}
+		c.log.Error("Failed to get raw value from cache",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return "", fmt.Errorf("failed to get raw value from cache: %w", err)
+	}
+	return val, nil
+}
+
+// compareAndDeleteScript deletes a key only if it still holds the value the
+// caller read.
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// CompareAndDelete deletes key only if it still holds oldValue and reports
+// whether it did.
+func (c *Client) CompareAndDelete(ctx context.Context, key, oldValue string) (bool, error) {
+	if err := c.breaker.allow(key); err != nil {
+		return false, err
+	}
+
+	deleted, err := compareAndDeleteScript.Run(ctx, c.client, []string{key}, oldValue).Int64()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to compare and delete cache value",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return false, fmt.Errorf("failed to compare and delete cache value: %w", err)
+	}
+	return deleted == 1, nil
+}
+
+// ScanStrings iterates over string keys matching pattern and calls fn with
+// each batch SCAN returns. In cluster mode every master is scanned and fn is
+// never called concurrently.
+func (c *Client) ScanStrings(ctx context.Context, pattern string, count int64, fn func(keys []string) error) error {
+	cluster, ok := c.client.(*redis.ClusterClient)
+	if !ok {
+		return c.scanStrings(ctx, c.client, pattern, count, fn)
+	}
+
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return c.scanStrings(ctx, node, pattern, count, func(keys []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(keys)
+		})
+	})
+}
+
+func (c *Client) scanStrings(ctx context.Context, node redis.Cmdable, pattern string, count int64, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+
+		keys, next, err := node.ScanType(ctx, cursor, pattern, count, "string").Result()
+		c.breaker.record(ctx, err)
+		if err != nil {
+			c.log.Error("Failed to scan cache keys",
+				slog.String("pattern", pattern),
+				slog.String("error", err.Error()))
+			return fmt.Errorf("failed to scan cache keys: %w", err)
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func (c *Client) ZAddLex(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	zMembers := make([]redis.Z, 0, len(members))
+	for _, member := range members {
+		zMembers = append(zMembers, redis.Z{Score: 0, Member: member})
+	}
+
+	if err := c.breaker.allow(key); err != nil {
+		return err
+	}
+
+	err := c.client.ZAdd(ctx, key, zMembers...).Err()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to add to sorted set",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to add to sorted set: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) ZRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		args = append(args, member)
+	}
+
+	if err := c.breaker.allow(key); err != nil {
+		return err
+	}
+
+	err := c.client.ZRem(ctx, key, args...).Err()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to remove from sorted set",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to remove from sorted set: %w", err)
+	}
+	return nil
+}
+
+// ZIncrBy adds increments to the scores of their members, trims the set to
+// the keep highest scored members and sets its TTL, in one pipeline. Unlike
+// other writes, failed increments do not get the key deleted once Redis is
+// back: lost increments only make the counts less accurate.
+func (c *Client) ZIncrBy(ctx context.Context, key string, increments map[string]float64, keep int64, ttl time.Duration) error {
+	if len(increments) == 0 {
+		return nil
+	}
+
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for member, increment := range increments {
+			pipe.ZIncrBy(ctx, key, increment, member)
+		}
+		if keep > 0 {
+			pipe.ZRemRangeByRank(ctx, key, 0, -keep-1)
+		}
+		if ttl > 0 {
+			pipe.PExpire(ctx, key, ttl)
+		}
+		return nil
+	})
+	c.breaker.record(ctx, err)
+	if err != nil {
+		c.log.Error("Failed to increment sorted set scores",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to increment sorted set scores: %w", err)
+	}
+	return nil
+}
+
+// ZUnionRevRange sums the scores of the sorted sets at keys into dest and
+// returns up to limit members with the highest sums, highest first. dest is
+// deleted again in the same transaction; in cluster mode all keys, dest
+// included, must share a hash slot.
+func (c *Client) ZUnionRevRange(ctx context.Context, keys []string, dest string, limit int) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	var members *redis.StringSliceCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, dest, &redis.ZStore{Keys: keys, Aggregate: "SUM"})
+		members = pipe.ZRevRange(ctx, dest, 0, int64(limit)-1)
+		pipe.Del(ctx, dest)
+		return nil
+	})
+	c.breaker.record(ctx, err)
+	if err != nil {
+		c.log.Error("Failed to range union of sorted sets by score",
+			slog.Any("keys", keys),
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to range union of sorted sets by score: %w", err)
+	}
+	return members.Val(), nil
+}
+
+// ZRangeByLexIfMember is ZRangeByLex that only returns members while
+// required is a member of the set too, and reports whether it was. Both are
+// read in one transaction.
+func (c *Client) ZRangeByLexIfMember(ctx context.Context, key, required, min, max string, limit int) ([]string, bool, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, false, err
+	}
+
+	var (
+		score   *redis.FloatCmd
+		members *redis.StringSliceCmd
+	)
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		score = pipe.ZScore(ctx, key, required)
+		members = pipe.ZRangeByLex(ctx, key, &redis.ZRangeBy{
+			Min:   min,
+			Max:   max,
+			Count: int64(limit),
+		})
+		return nil
+	})
+	c.breaker.record(ctx, err)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Error("Failed to range sorted set by lex",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return nil, false, fmt.Errorf("failed to range sorted set by lex: %w", err)
+	}
+	if score.Err() != nil {
+		return nil, false, nil
+	}
+	return members.Val(), true, nil
+}
+
+var zAddUnlessMemberScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+    return 0
+end
+redis.call('ZADD', KEYS[1], 0, ARGV[2])
+return 1
+`)
+
+// ZAddUnlessMember adds member with score 0 unless present is already a
+// member, and reports whether it did.
+func (c *Client) ZAddUnlessMember(ctx context.Context, key, present, member string) (bool, error) {
+	if err := c.breaker.allow(key); err != nil {
+		return false, err
+	}
+
+	added, err := zAddUnlessMemberScript.Run(ctx, c.client, []string{key}, present, member).Int64()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to add to sorted set",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return false, fmt.Errorf("failed to add to sorted set: %w", err)
+	}
+	return added == 1, nil
+}
+
+var zReplaceMemberScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+    return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZADD', KEYS[1], 0, ARGV[2])
+return 1
+`)
+
+// ZReplaceMember swaps oldMember for newMember, with score 0, only if
+// oldMember is still in the set, and reports whether it did.
+func (c *Client) ZReplaceMember(ctx context.Context, key, oldMember, newMember string) (bool, error) {
+	if err := c.breaker.allow(key); err != nil {
+		return false, err
+	}
+
+	replaced, err := zReplaceMemberScript.Run(ctx, c.client, []string{key}, oldMember, newMember).Int64()
+	c.breaker.record(ctx, err, key)
+	if err != nil {
+		c.log.Error("Failed to replace sorted set member",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return false, fmt.Errorf("failed to replace sorted set member: %w", err)
+	}
+	return replaced == 1, nil
+}
+
+func (c *Client) Publish(ctx context.Context, channel string, message []byte) error {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+
+	err := c.client.Publish(ctx, channel, message).Err()
+	c.breaker.record(ctx, err)
+	if err != nil {
+		c.log.Error("Failed to publish message",
+			slog.String("channel", channel),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to channel. When Redis confirms in time, messages
+// published after it returns are delivered; otherwise the subscription is
+// kept and go-redis establishes it once Redis is reachable again. The caller
+// must close the result.
+func (c *Client) Subscribe(ctx context.Context, channel string) *redis.PubSub {
+	sub := c.client.Subscribe(ctx, channel)
+	if !c.breaker.available() {
+		return sub
+	}
+
+	receiveCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := sub.Receive(receiveCtx); err != nil {
+		c.log.Warn("Subscription not confirmed, will retry in the background",
+			slog.String("channel", channel),
+			slog.String("error", err.Error()))
+	}
+	return sub
+}
+
 func (c *Client) Close() error {
+	c.breaker.stop()
+
 	if err := c.client.Close(); err != nil {
 		c.log.Error("Failed to close Redis connection", slog.String("error", err.Error()))
 		return fmt.Errorf("failed to close Redis connection: %w", err)
@@ -121,6 +822,22 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// deleteBatches removes keys in chunks, bypassing the circuit breaker. The
+// breaker uses it to clean up after writes lost during an outage.
+func (c *Client) deleteBatches(ctx context.Context, keys []string) error {
+	const batchSize = 500
+	for start := 0; start < len(keys); start += batchSize {
+		end := min(start+batchSize, len(keys))
+		if _, err := c.del(ctx, keys[start:end]); err != nil {
+			return fmt.Errorf("failed to delete stale cache keys: %w", err)
+		}
+	}
+
+	c.log.Info("Deleted cache keys left stale by failed writes", slog.Int("count", len(keys)))
+	return nil
+}
+
+// Ping talks to Redis directly, bypassing the circuit breaker.
 func (c *Client) Ping(ctx context.Context) error {
 	if err := c.client.Ping(ctx).Err(); err != nil {
 		c.log.Error("Redis ping failed", slog.String("error", err.Error()))
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// A user's keys cannot all share a hash slot: the email and username pointer
+// keys are looked up before the id is known, so they cannot carry the id's
+// hash tag. Multi-key commands therefore run as-is, and stay atomic, when
+// their keys hash to one slot or outside cluster mode, and are split into
+// pipelined single-key commands otherwise. Each single-key write is still
+// version-checked, and pointer reads verify their target, so a partially
+// applied SetUser only costs a miss.
+
+func (c *Client) mget(ctx context.Context, keys []string) ([]interface{}, error) {
+	if !c.cluster || sameSlot(keys) {
+		return c.client.MGet(ctx, keys...).Result()
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	vals := make([]interface{}, len(keys))
+	for i, cmd := range cmds {
+		if val, err := cmd.Result(); err == nil {
+			vals[i] = val
+		}
+	}
+	return vals, nil
+}
+
+// setIfNotOlder runs setIfNotOlderScript; args are the version, the TTL and
+// one payload per key.
+func (c *Client) setIfNotOlder(ctx context.Context, keys []string, args []interface{}) (int, error) {
+	if !c.cluster || sameSlot(keys) {
+		return setIfNotOlderScript.Run(ctx, c.client, keys, args...).Int()
+	}
+
+	cmds := make([]*redis.Cmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = setIfNotOlderScript.Eval(ctx, pipe, []string{key}, args[0], args[1], args[i+2])
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	stored := 0
+	for _, cmd := range cmds {
+		n, err := cmd.Int()
+		if err != nil {
+			return stored, err
+		}
+		stored += n
+	}
+	return stored, nil
+}
+
+func (c *Client) del(ctx context.Context, keys []string) (int64, error) {
+	if !c.cluster || sameSlot(keys) {
+		return c.client.Del(ctx, keys...).Result()
+	}
+
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.Val()
+	}
+	return deleted, nil
+}
+
+func addresses(cfg config.Redis) []string {
+	if cfg.Mode == ModeSentinel || cfg.Mode == ModeCluster {
+		return cfg.Addresses
+	}
+	return []string{cfg.Address + ":" + strconv.Itoa(cfg.Port)}
+}
+
+// clusterSlot is the Redis Cluster hash slot of key: the CRC16 (XMODEM) of
+// its hash tag, the part between the first "{" and the next "}" if that is
+// not empty, or of the whole key otherwise.
+func clusterSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) & 16383
+}
+
+func sameSlot(keys []string) bool {
+	for _, key := range keys[1:] {
+		if clusterSlot(key) != clusterSlot(keys[0]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,333 @@
+package redis
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	pb "github.com/soloda1/pinstack-proto-definitions/gen/go/pinstack-proto-definitions/user/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	CodecJSON     = "json"
+	CodecProtobuf = "protobuf"
+)
+
+// Every encoded value starts with a format byte naming the codec and schema
+// version that wrote it. JSON needs no extra byte because its payloads are
+// objects and already start with '{'. The high bit marks a flate-compressed
+// body. Entries in a format this build does not know are treated as misses,
+// so bumping a codec's format byte after an incompatible schema change simply
+// retires every entry written before.
+const (
+	formatJSON     byte = '{'
+	formatProtoV1  byte = 0x01
+	compressedFlag byte = 0x80
+)
+
+var (
+	errUnknownFormat   = errors.New("unknown cache entry format")
+	errUnsupportedType = errors.New("type not supported by codec")
+)
+
+// Codec encodes and decodes cache entries. A codec that only knows some types
+// returns errUnsupportedType for the rest, which are then stored as JSON.
+type Codec interface {
+	Format() byte
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var codecs = map[byte]Codec{
+	formatJSON:    JSONCodec{},
+	formatProtoV1: ProtoCodec{},
+}
+
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case "", CodecProtobuf:
+		return ProtoCodec{}, nil
+	case CodecJSON:
+		return JSONCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache codec %q", name)
+	}
+}
+
+// Flate writers and readers allocate large buffers, so they are reused.
+var (
+	flateWriters = sync.Pool{New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	}}
+	flateReaders = sync.Pool{New: func() interface{} {
+		return flate.NewReader(bytes.NewReader(nil))
+	}}
+)
+
+// encoder writes values with one codec, compressing bodies larger than
+// compressAbove bytes (0 disables compression).
+type encoder struct {
+	codec         Codec
+	compressAbove int
+}
+
+func (e encoder) encode(v interface{}) ([]byte, error) {
+	codec := e.codec
+	body, err := codec.Marshal(v)
+	if errors.Is(err, errUnsupportedType) {
+		codec = JSONCodec{}
+		body, err = codec.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	format := codec.Format()
+	if e.compressAbove > 0 && len(body) > e.compressAbove {
+		var buf bytes.Buffer
+		buf.WriteByte(format | compressedFlag)
+		w := flateWriters.Get().(*flate.Writer)
+		defer flateWriters.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	if format == formatJSON {
+		return body, nil
+	}
+	return append([]byte{format}, body...), nil
+}
+
+// decode reads a value written by any known codec, whatever the encoder is
+// currently configured with, so switching codecs needs no cache flush.
+func decode(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return errUnknownFormat
+	}
+
+	format := data[0]
+	var body []byte
+	switch {
+	case format&compressedFlag != 0:
+		format &^= compressedFlag
+		r := flateReaders.Get().(io.ReadCloser)
+		defer flateReaders.Put(r)
+		if err := r.(flate.Resetter).Reset(bytes.NewReader(data[1:]), nil); err != nil {
+			return fmt.Errorf("failed to decompress cache entry: %w", err)
+		}
+		inflated, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to decompress cache entry: %w", err)
+		}
+		body = inflated
+	case format == formatJSON:
+		body = data
+	default:
+		body = data[1:]
+	}
+
+	codec, ok := codecs[format]
+	if !ok {
+		return errUnknownFormat
+	}
+	return codec.Unmarshal(body, v)
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Format() byte { return formatJSON }
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// ProtoCodec stores user entries as the service's pb.User message inside a
+// small envelope carrying the cache-only fields:
+//
+//	1: pb.User (bytes)   2: pointer id (varint)
+//	3: not found (bool)  4: expires at, unix nanoseconds (varint)
+//	5: version (varint)
+//
+// The version lives in the envelope because pb.User has no field for it.
+//
+// Both entry types share the envelope, so a pointer decodes from a full user
+// entry the same way the JSON layout allows.
+type ProtoCodec struct{}
+
+const (
+	envelopeUser protowire.Number = iota + 1
+	envelopePointerID
+	envelopeNotFound
+	envelopeExpiresAt
+	envelopeVersion
+)
+
+func (ProtoCodec) Format() byte { return formatProtoV1 }
+
+func (ProtoCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf []byte
+	switch entry := v.(type) {
+	case *cachedUser:
+		if !entry.NotFound {
+			user, err := proto.Marshal(toProtoUser(entry))
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal user: %w", err)
+			}
+			buf = protowire.AppendTag(buf, envelopeUser, protowire.BytesType)
+			buf = protowire.AppendBytes(buf, user)
+		}
+		if !entry.ExpiresAt.IsZero() {
+			buf = protowire.AppendTag(buf, envelopeExpiresAt, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(entry.ExpiresAt.UnixNano()))
+		}
+		if entry.Version != 0 {
+			buf = protowire.AppendTag(buf, envelopeVersion, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(entry.Version))
+		}
+		buf = appendNotFound(buf, entry.NotFound)
+	case *cachedPointer:
+		if entry.ID != 0 {
+			buf = protowire.AppendTag(buf, envelopePointerID, protowire.VarintType)
+			buf = protowire.AppendVarint(buf, uint64(entry.ID))
+		}
+		buf = appendNotFound(buf, entry.NotFound)
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnsupportedType, v)
+	}
+	return buf, nil
+}
+
+func (ProtoCodec) Unmarshal(data []byte, v interface{}) error {
+	var (
+		user      pb.User
+		pointerID int64
+		notFound  bool
+		expiresAt time.Time
+		version   int64
+	)
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch {
+		case num == envelopeUser && typ == protowire.BytesType:
+			raw, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if err := proto.Unmarshal(raw, &user); err != nil {
+				return fmt.Errorf("failed to unmarshal user: %w", err)
+			}
+			data = data[n:]
+		case typ == protowire.VarintType && num != envelopeUser:
+			val, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			switch num {
+			case envelopePointerID:
+				pointerID = int64(val)
+			case envelopeNotFound:
+				notFound = protowire.DecodeBool(val)
+			case envelopeExpiresAt:
+				expiresAt = time.Unix(0, int64(val)).UTC()
+			case envelopeVersion:
+				version = int64(val)
+			}
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+
+	switch entry := v.(type) {
+	case *cachedUser:
+		*entry = *fromProtoUser(&user)
+		if entry.ID == 0 {
+			entry.ID = pointerID
+		}
+		entry.ExpiresAt = expiresAt
+		entry.Version = version
+		entry.NotFound = notFound
+	case *cachedPointer:
+		entry.ID = pointerID
+		if entry.ID == 0 {
+			entry.ID = user.GetId()
+		}
+		entry.NotFound = notFound
+	default:
+		return fmt.Errorf("%w: %T", errUnsupportedType, v)
+	}
+	return nil
+}
+
+func appendNotFound(buf []byte, notFound bool) []byte {
+	if !notFound {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, envelopeNotFound, protowire.VarintType)
+	return protowire.AppendVarint(buf, protowire.EncodeBool(true))
+}
+
+func toProtoUser(entry *cachedUser) *pb.User {
+	return &pb.User{
+		Id:        entry.ID,
+		Username:  entry.Username,
+		Email:     entry.Email,
+		FullName:  entry.FullName,
+		Bio:       entry.Bio,
+		AvatarUrl: entry.AvatarURL,
+		CreatedAt: toTimestamp(entry.CreatedAt),
+		UpdatedAt: toTimestamp(entry.UpdatedAt),
+	}
+}
+
+func fromProtoUser(user *pb.User) *cachedUser {
+	return &cachedUser{
+		ID:        user.GetId(),
+		Username:  user.GetUsername(),
+		Email:     user.GetEmail(),
+		FullName:  user.FullName,
+		Bio:       user.Bio,
+		AvatarURL: user.AvatarUrl,
+		CreatedAt: fromTimestamp(user.GetCreatedAt()),
+		UpdatedAt: fromTimestamp(user.GetUpdatedAt()),
+	}
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
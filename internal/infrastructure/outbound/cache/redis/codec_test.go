@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCachedUser(bio string) *cachedUser {
+	fullName := "Alice Example"
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &cachedUser{
+		ID:        42,
+		Username:  "alice",
+		Email:     "alice@example.com",
+		FullName:  &fullName,
+		Bio:       &bio,
+		CreatedAt: now.Add(-time.Hour),
+		UpdatedAt: now,
+		Version:   7,
+		ExpiresAt: now.Add(30 * time.Minute),
+	}
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder encoder
+	}{
+		{name: "json", encoder: encoder{codec: JSONCodec{}}},
+		{name: "protobuf", encoder: encoder{codec: ProtoCodec{}}},
+		{name: "json compressed", encoder: encoder{codec: JSONCodec{}, compressAbove: 1}},
+		{name: "protobuf compressed", encoder: encoder{codec: ProtoCodec{}, compressAbove: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := testCachedUser("likes pins")
+			data, err := tt.encoder.encode(user)
+			require.NoError(t, err)
+			var gotUser cachedUser
+			require.NoError(t, decode(data, &gotUser))
+			assert.Equal(t, user, &gotUser)
+
+			tombstone := &cachedUser{NotFound: true}
+			data, err = tt.encoder.encode(tombstone)
+			require.NoError(t, err)
+			var gotTombstone cachedUser
+			require.NoError(t, decode(data, &gotTombstone))
+			assert.Equal(t, tombstone, &gotTombstone)
+
+			pointer := &cachedPointer{ID: 42}
+			data, err = tt.encoder.encode(pointer)
+			require.NoError(t, err)
+			var gotPointer cachedPointer
+			require.NoError(t, decode(data, &gotPointer))
+			assert.Equal(t, pointer, &gotPointer)
+		})
+	}
+}
+
+func TestCodec_FallsBackToJSONForOtherTypes(t *testing.T) {
+	e := encoder{codec: ProtoCodec{}}
+	data, err := e.encode([]int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2,3]", string(data))
+}
+
+func TestCodec_UnknownFormat(t *testing.T) {
+	var user cachedUser
+	assert.ErrorIs(t, decode([]byte{0x02, 0x08, 0x01}, &user), errUnknownFormat)
+	assert.ErrorIs(t, decode(nil, &user), errUnknownFormat)
+}
+
+func TestCodec_CompressesLargeEntries(t *testing.T) {
+	e := encoder{codec: ProtoCodec{}, compressAbove: 256}
+	bio := strings.Repeat("pins and boards ", 200)
+
+	small, err := e.encode(testCachedUser("short"))
+	require.NoError(t, err)
+	assert.Equal(t, formatProtoV1, small[0])
+
+	large, err := e.encode(testCachedUser(bio))
+	require.NoError(t, err)
+	assert.Equal(t, formatProtoV1|compressedFlag, large[0])
+	assert.Less(t, len(large), len(bio))
+
+	var got cachedUser
+	require.NoError(t, decode(large, &got))
+	assert.Equal(t, bio, *got.Bio)
+}
+
+func BenchmarkCodec(b *testing.B) {
+	users := map[string]*cachedUser{
+		"small": testCachedUser("likes pins"),
+		"large": testCachedUser(strings.Repeat("pins and boards ", 200)),
+	}
+	encoders := map[string]encoder{
+		"json":                {codec: JSONCodec{}},
+		"protobuf":            {codec: ProtoCodec{}},
+		"protobuf_compressed": {codec: ProtoCodec{}, compressAbove: 1024},
+	}
+
+	for userName, user := range users {
+		for encoderName, e := range encoders {
+			data, err := e.encode(user)
+			require.NoError(b, err)
+
+			b.Run(encoderName+"/"+userName+"/encode", func(b *testing.B) {
+				b.ReportAllocs()
+				b.ReportMetric(float64(len(data)), "bytes/entry")
+				for i := 0; i < b.N; i++ {
+					if _, err := e.encode(user); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(encoderName+"/"+userName+"/decode", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					var got cachedUser
+					if err := decode(data, &got); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"pinstack-user-service/internal/domain/models"
+	ports "pinstack-user-service/internal/domain/ports/output"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+)
+
+// DriftKind classifies how a cache entry disagrees with the database.
+type DriftKind string
+
+const (
+	// DriftStale is a cached user whose fields differ from its row.
+	DriftStale DriftKind = "stale"
+	// DriftOrphan is a cached user whose row no longer exists.
+	DriftOrphan DriftKind = "orphan"
+	// DriftHiddenUser is a not-found entry for a user that exists.
+	DriftHiddenUser DriftKind = "hidden_user"
+	// DriftDanglingPointer is an email or username key pointing at a user
+	// that does not exist or no longer has that email or username.
+	DriftDanglingPointer DriftKind = "dangling_pointer"
+	// DriftUndecodable is an entry this build cannot decode.
+	DriftUndecodable DriftKind = "undecodable"
+)
+
+// UserSource is the source of truth the cache is checked against, normally
+// the Postgres repository.
+type UserSource interface {
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+type CheckOptions struct {
+	BatchSize int64
+	// SampleRate is the fraction of scanned keys that get checked; values
+	// outside (0, 1) check every key.
+	SampleRate float64
+	// Repair deletes drifted entries so the service reloads them from the
+	// database on the next read.
+	Repair bool
+}
+
+type CheckStats struct {
+	Scanned  int
+	Checked  int
+	Drifted  int
+	Repaired int
+	Failed   int
+	ByKind   map[DriftKind]int
+}
+
+type drift struct {
+	kind   DriftKind
+	detail string
+}
+
+// CheckConsistency scans user:* entries and compares each against source.
+// An entry is only reported when it still holds the value that was checked,
+// so writes racing with the scan are not mistaken for drift, and repairs are
+// compare-and-delete for the same reason.
+func CheckConsistency(ctx context.Context, client *Client, source UserSource, opts CheckOptions, log ports.Logger) (CheckStats, error) {
+	stats := CheckStats{ByKind: make(map[DriftKind]int)}
+
+	err := client.ScanStrings(ctx, userCacheKeyPrefix+"*", opts.BatchSize, func(keys []string) error {
+		for _, key := range keys {
+			stats.Scanned++
+			if opts.SampleRate > 0 && opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+				continue
+			}
+			stats.Checked++
+
+			raw, found, err := checkEntry(ctx, client, source, key)
+			if err != nil {
+				log.Warn("Failed to check cache entry",
+					slog.String("key", key),
+					slog.String("error", err.Error()))
+				stats.Failed++
+				continue
+			}
+			if found == nil {
+				continue
+			}
+
+			stats.Drifted++
+			stats.ByKind[found.kind]++
+			log.Warn("Cache entry disagrees with database",
+				slog.String("key", key),
+				slog.String("kind", string(found.kind)),
+				slog.String("detail", found.detail))
+
+			if !opts.Repair {
+				continue
+			}
+			deleted, err := client.CompareAndDelete(ctx, key, raw)
+			if err != nil {
+				log.Warn("Failed to repair cache entry",
+					slog.String("key", key),
+					slog.String("error", err.Error()))
+				stats.Failed++
+				continue
+			}
+			if deleted {
+				stats.Repaired++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// checkEntry returns the raw value it checked and the drift found in it, if
+// any. Entries that change while being checked are not reported.
+func checkEntry(ctx context.Context, client *Client, source UserSource, key string) (string, *drift, error) {
+	raw, err := client.GetRaw(ctx, key)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrCacheMiss) {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+
+	var found *drift
+	if email, ok := userKeyPart(key, userEmailCacheKeyPrefix); ok {
+		found, err = checkPointer(ctx, source, raw, "email", email, source.GetByEmail,
+			func(user *models.User) string { return user.Email })
+	} else if username, ok := userKeyPart(key, userUsernameCacheKeyPrefix); ok {
+		found, err = checkPointer(ctx, source, raw, "username", username, source.GetByUsername,
+			func(user *models.User) string { return user.Username })
+	} else if id, ok := parseUserKey(key); ok {
+		found, err = checkUser(ctx, source, raw, id)
+	} else {
+		// Keys from before user keys were hash-tagged are left to the purge
+		// tool.
+		return "", nil, nil
+	}
+	if err != nil || found == nil {
+		return raw, nil, err
+	}
+
+	current, err := client.GetRaw(ctx, key)
+	if err != nil && !errors.Is(err, custom_errors.ErrCacheMiss) {
+		return "", nil, err
+	}
+	if current != raw {
+		return raw, nil, nil
+	}
+	return raw, found, nil
+}
+
+func checkUser(ctx context.Context, source UserSource, raw string, id int64) (*drift, error) {
+	_, payload := splitVersion(raw)
+	var cached cachedUser
+	if err := decode([]byte(payload), &cached); err != nil {
+		return &drift{kind: DriftUndecodable, detail: err.Error()}, nil
+	}
+
+	user, err := source.GetByID(ctx, id)
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		if cached.NotFound {
+			return nil, nil
+		}
+		return &drift{kind: DriftOrphan, detail: fmt.Sprintf("user %d does not exist", id)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user %d: %w", id, err)
+	}
+
+	if cached.NotFound {
+		return &drift{kind: DriftHiddenUser, detail: fmt.Sprintf("user %d exists", id)}, nil
+	}
+	if fields := diffUser(&cached, user); len(fields) > 0 {
+		return &drift{kind: DriftStale, detail: "differs in " + strings.Join(fields, ", ")}, nil
+	}
+	return nil, nil
+}
+
+func checkPointer(
+	ctx context.Context,
+	source UserSource,
+	raw, kind, value string,
+	lookup func(ctx context.Context, value string) (*models.User, error),
+	field func(*models.User) string,
+) (*drift, error) {
+	_, payload := splitVersion(raw)
+	var pointer cachedPointer
+	if err := decode([]byte(payload), &pointer); err != nil {
+		return &drift{kind: DriftUndecodable, detail: err.Error()}, nil
+	}
+
+	if pointer.NotFound {
+		user, err := lookup(ctx, value)
+		if errors.Is(err, custom_errors.ErrUserNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user by %s: %w", kind, err)
+		}
+		return &drift{kind: DriftHiddenUser, detail: fmt.Sprintf("user %d has this %s", user.ID, kind)}, nil
+	}
+
+	user, err := source.GetByID(ctx, pointer.ID)
+	if errors.Is(err, custom_errors.ErrUserNotFound) {
+		return &drift{kind: DriftDanglingPointer, detail: fmt.Sprintf("user %d does not exist", pointer.ID)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user %d: %w", pointer.ID, err)
+	}
+	if field(user) != value {
+		return &drift{kind: DriftDanglingPointer, detail: fmt.Sprintf("user %d has %s %q", user.ID, kind, field(user))}, nil
+	}
+	return nil, nil
+}
+
+// parseUserKey extracts the id from a user:{id} key.
+func parseUserKey(key string) (int64, bool) {
+	part, ok := userKeyPart(key, userCacheKeyPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(part, 10, 64)
+	return id, err == nil
+}
+
+// diffUser lists the fields in which a cached user differs from its row.
+func diffUser(cached *cachedUser, user *models.User) []string {
+	var fields []string
+	if cached.Username != user.Username {
+		fields = append(fields, "username")
+	}
+	if cached.Email != user.Email {
+		fields = append(fields, "email")
+	}
+	if !equalOptional(cached.FullName, user.FullName) {
+		fields = append(fields, "full_name")
+	}
+	if !equalOptional(cached.Bio, user.Bio) {
+		fields = append(fields, "bio")
+	}
+	if !equalOptional(cached.AvatarURL, user.AvatarURL) {
+		fields = append(fields, "avatar_url")
+	}
+	if !cached.CreatedAt.Equal(user.CreatedAt) {
+		fields = append(fields, "created_at")
+	}
+	if !cached.UpdatedAt.Equal(user.UpdatedAt) {
+		fields = append(fields, "updated_at")
+	}
+	// Entries written before users were versioned carry no version, and
+	// reads treat them as misses; report them so -repair clears them.
+	if cached.Version != user.Version {
+		fields = append(fields, "version")
+	}
+	return fields
+}
+
+// equalOptional treats a missing value and an empty one alike; clients
+// cannot tell them apart either.
+func equalOptional(a, b *string) bool {
+	var x, y string
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	return x == y
+}
@@ -0,0 +1,158 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pinstack-user-service/internal/domain/models"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUserSource map[int64]*models.User
+
+func (s fakeUserSource) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	if user, ok := s[id]; ok {
+		return user, nil
+	}
+	return nil, custom_errors.ErrUserNotFound
+}
+
+func (s fakeUserSource) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	for _, user := range s {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, custom_errors.ErrUserNotFound
+}
+
+func (s fakeUserSource) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range s {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, custom_errors.ErrUserNotFound
+}
+
+func encodeEntry(t *testing.T, v interface{}) string {
+	data, err := encoder{codec: ProtoCodec{}}.encode(v)
+	require.NoError(t, err)
+	return formatVersion(1) + string(data)
+}
+
+func TestCheckUser(t *testing.T) {
+	ctx := context.Background()
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bio := "likes pins"
+	source := fakeUserSource{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com", Bio: &bio, UpdatedAt: updatedAt, Version: 3},
+	}
+
+	tests := []struct {
+		name  string
+		id    int64
+		entry *cachedUser
+		want  DriftKind
+	}{
+		{
+			name:  "in sync",
+			id:    1,
+			entry: &cachedUser{ID: 1, Username: "alice", Email: "alice@example.com", Bio: &bio, UpdatedAt: updatedAt, Version: 3},
+		},
+		{
+			name:  "unversioned",
+			id:    1,
+			entry: &cachedUser{ID: 1, Username: "alice", Email: "alice@example.com", Bio: &bio, UpdatedAt: updatedAt},
+			want:  DriftStale,
+		},
+		{
+			name:  "stale",
+			id:    1,
+			entry: &cachedUser{ID: 1, Username: "alice_old", Email: "alice@example.com", UpdatedAt: updatedAt.Add(-time.Hour)},
+			want:  DriftStale,
+		},
+		{
+			name:  "orphan",
+			id:    2,
+			entry: &cachedUser{ID: 2, Username: "bob", Email: "bob@example.com"},
+			want:  DriftOrphan,
+		},
+		{
+			name:  "tombstone for existing user",
+			id:    1,
+			entry: &cachedUser{NotFound: true},
+			want:  DriftHiddenUser,
+		},
+		{
+			name:  "tombstone for missing user",
+			id:    2,
+			entry: &cachedUser{NotFound: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := checkUser(ctx, source, encodeEntry(t, tt.entry), tt.id)
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, found)
+				return
+			}
+			require.NotNil(t, found)
+			assert.Equal(t, tt.want, found.kind)
+		})
+	}
+}
+
+func TestCheckPointer(t *testing.T) {
+	ctx := context.Background()
+	source := fakeUserSource{1: {ID: 1, Username: "alice", Email: "alice@example.com"}}
+	username := func(user *models.User) string { return user.Username }
+
+	tests := []struct {
+		name     string
+		username string
+		entry    *cachedPointer
+		want     DriftKind
+	}{
+		{name: "in sync", username: "alice", entry: &cachedPointer{ID: 1}},
+		{name: "renamed user", username: "alice_old", entry: &cachedPointer{ID: 1}, want: DriftDanglingPointer},
+		{name: "deleted user", username: "bob", entry: &cachedPointer{ID: 2}, want: DriftDanglingPointer},
+		{name: "tombstone for taken username", username: "alice", entry: &cachedPointer{NotFound: true}, want: DriftHiddenUser},
+		{name: "tombstone for free username", username: "bob", entry: &cachedPointer{NotFound: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := checkPointer(ctx, source, encodeEntry(t, tt.entry), "username", tt.username,
+				source.GetByUsername, username)
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, found)
+				return
+			}
+			require.NotNil(t, found)
+			assert.Equal(t, tt.want, found.kind)
+		})
+	}
+}
+
+func TestParseUserKey(t *testing.T) {
+	id, ok := parseUserKey("user:{42}")
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), id)
+
+	_, ok = parseUserKey("user:42")
+	assert.False(t, ok, "keys from before hash tags are not checked")
+
+	_, ok = parseUserKey("user:email:{alice@example.com}")
+	assert.False(t, ok)
+
+	_, ok = parseUserKey("user:usernames")
+	assert.False(t, ok)
+}
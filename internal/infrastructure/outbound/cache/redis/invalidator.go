@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+)
+
+// Invalidator broadcasts cache invalidations over a Redis pub/sub channel.
+type Invalidator struct {
+	client  *Client
+	channel string
+	log     ports.Logger
+}
+
+func NewInvalidator(client *Client, channel string, log ports.Logger) *Invalidator {
+	return &Invalidator{
+		client:  client,
+		channel: channel,
+		log:     log,
+	}
+}
+
+func (i *Invalidator) Publish(ctx context.Context, message []byte) error {
+	return i.client.Publish(ctx, i.channel, message)
+}
+
+// Subscribe delivers messages to handler from a background goroutine until
+// ctx is done. go-redis (re)establishes the subscription on its own whenever
+// the connection to Redis is lost, so this never fails.
+func (i *Invalidator) Subscribe(ctx context.Context, handler func(message []byte)) error {
+	sub := i.client.Subscribe(ctx, i.channel)
+
+	i.log.Info("Subscribed to cache invalidations", slog.String("channel", i.channel))
+
+	go func() {
+		defer func() {
+			if err := sub.Close(); err != nil {
+				i.log.Warn("Failed to close invalidation subscription", slog.String("error", err.Error()))
+			}
+		}()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				handler([]byte(msg.Payload))
+			}
+		}
+	}()
+
+	return nil
+}
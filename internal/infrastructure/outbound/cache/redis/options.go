@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// newUniversalClient builds the go-redis client matching cfg.Mode. All three
+// implement redis.UniversalClient, so the rest of the package does not care
+// which one it talks to, except where cluster needs keys split by slot.
+func newUniversalClient(cfg config.Redis) (redis.UniversalClient, error) {
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Mode {
+	case "", ModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:      fmt.Sprintf("%s:%d", cfg.Address, cfg.Port),
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			DB:        cfg.DB,
+			PoolSize:  cfg.PoolSize,
+			TLSConfig: tlsConfig,
+		}), nil
+
+	case ModeSentinel:
+		if cfg.MasterName == "" || len(cfg.Addresses) == 0 {
+			return nil, errors.New("sentinel mode requires master_name and sentinel addresses")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addresses,
+			SentinelUsername: cfg.SentinelUsername,
+			SentinelPassword: cfg.SentinelPassword,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			TLSConfig:        tlsConfig,
+		}), nil
+
+	case ModeCluster:
+		if len(cfg.Addresses) == 0 {
+			return nil, errors.New("cluster mode requires seed node addresses")
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     cfg.Addresses,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			PoolSize:  cfg.PoolSize,
+			TLSConfig: tlsConfig,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
+}
+
+func newTLSConfig(cfg config.RedisTLS) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("redis CA file contains no certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
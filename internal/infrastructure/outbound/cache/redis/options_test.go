@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"testing"
+
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUniversalClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Redis
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "standalone by default",
+			cfg:  config.Redis{Address: "localhost", Port: 6379},
+			want: &redis.Client{},
+		},
+		{
+			name: "sentinel",
+			cfg:  config.Redis{Mode: ModeSentinel, MasterName: "mymaster", Addresses: []string{"sentinel:26379"}},
+			want: &redis.Client{},
+		},
+		{
+			name:    "sentinel without master name",
+			cfg:     config.Redis{Mode: ModeSentinel, Addresses: []string{"sentinel:26379"}},
+			wantErr: true,
+		},
+		{
+			name: "cluster",
+			cfg:  config.Redis{Mode: ModeCluster, Addresses: []string{"node1:6379", "node2:6379"}},
+			want: &redis.ClusterClient{},
+		},
+		{
+			name:    "cluster without seed nodes",
+			cfg:     config.Redis{Mode: ModeCluster},
+			wantErr: true,
+		},
+		{
+			name:    "unknown mode",
+			cfg:     config.Redis{Mode: "ring"},
+			wantErr: true,
+		},
+		{
+			name:    "missing CA file",
+			cfg:     config.Redis{TLS: config.RedisTLS{Enabled: true, CAFile: "/nonexistent/ca.pem"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newUniversalClient(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer client.Close()
+			assert.IsType(t, tt.want, client)
+		})
+	}
+}
+
+func TestHashTagSpreadsUserKeys(t *testing.T) {
+	u := &UserCache{}
+	assert.Equal(t, "user:{42}", u.getUserKey(42))
+	assert.Equal(t, clusterSlot("42"), clusterSlot(u.getUserKey(42)))
+	assert.Equal(t, clusterSlot("alice@example.com"), clusterSlot(u.getUserEmailKey("alice@example.com")))
+	assert.Equal(t, clusterSlot("alice"), clusterSlot(u.getUserUsernameKey("alice")))
+	assert.NotEqual(t, clusterSlot(u.getUserKey(42)), clusterSlot(u.getUserKey(7)))
+}
+
+func TestClusterSlot(t *testing.T) {
+	// Reference values from the Redis Cluster specification.
+	assert.Equal(t, 0x31C3, clusterSlot("123456789"))
+	assert.Equal(t, clusterSlot("user1000"), clusterSlot("{user1000}.following"))
+	assert.NotEqual(t, clusterSlot("bar"), clusterSlot("foo{}{bar}"), "an empty tag hashes the whole key")
+	assert.False(t, sameSlot([]string{"user:1", "user:2", "user:3"}))
+}
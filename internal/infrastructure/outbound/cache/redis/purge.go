@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+)
+
+type PurgeStats struct {
+	Scanned int
+	Deleted int
+	Skipped int
+}
+
+// PurgePasswordHashes deletes user entries still stored under the key layout
+// used before user keys were hash-tagged (user:42, user:email:<email>,
+// user:username:<username>). Those entries may carry password hashes, and the
+// service no longer reads or writes them, so they are dropped rather than
+// rewritten. Current entries and other keys under user: are left alone.
+func PurgePasswordHashes(ctx context.Context, client *Client, batchSize int64, dryRun bool, log ports.Logger) (PurgeStats, error) {
+	var stats PurgeStats
+
+	err := client.ScanStrings(ctx, userCacheKeyPrefix+"*", batchSize, func(keys []string) error {
+		stats.Scanned += len(keys)
+
+		legacy := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if isLegacyUserKey(key) {
+				legacy = append(legacy, key)
+			}
+		}
+		if len(legacy) == 0 {
+			return nil
+		}
+		if dryRun {
+			stats.Deleted += len(legacy)
+			return nil
+		}
+
+		if err := client.Delete(ctx, legacy...); err != nil {
+			log.Warn("Failed to delete legacy cache entries",
+				slog.Any("keys", legacy),
+				slog.String("error", err.Error()))
+			stats.Skipped += len(legacy)
+			return nil
+		}
+		stats.Deleted += len(legacy)
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// isLegacyUserKey reports whether key is a user entry without a hash tag.
+func isLegacyUserKey(key string) bool {
+	for _, prefix := range []string{userEmailCacheKeyPrefix, userUsernameCacheKeyPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			_, tagged := userKeyPart(key, prefix)
+			return !tagged
+		}
+	}
+
+	id, ok := strings.CutPrefix(key, userCacheKeyPrefix)
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseInt(id, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"pinstack-user-service/internal/domain/models"
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgePasswordHashes(t *testing.T) {
+	ctx := context.Background()
+	u, server := newTestUserCache(t, config.Redis{})
+	log := logger.New("test")
+
+	// Entries as the service wrote them before user keys were hash-tagged.
+	legacy := `{"id":1,"username":"alice","email":"alice@example.com","password":"hash","version":1,` +
+		`"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}`
+	legacyKeys := []string{"user:1", "user:email:alice@example.com", "user:username:alice", "user:2"}
+	require.NoError(t, server.Set("user:1", formatVersion(1)+legacy))
+	require.NoError(t, server.Set("user:email:alice@example.com", legacy))
+	require.NoError(t, server.Set("user:username:alice", legacy))
+	require.NoError(t, server.Set("user:2", "{not json"))
+	require.NoError(t, server.Set("session:1", legacy))
+	_, err := server.ZAdd(usernameIndexKey, 0, "alice")
+	require.NoError(t, err)
+	require.NoError(t, u.SetUser(ctx, &models.User{ID: 3, Username: "bob", Email: "bob@example.com", Version: 1}))
+
+	t.Run("dry run", func(t *testing.T) {
+		stats, err := PurgePasswordHashes(ctx, u.client, 2, true, log)
+		require.NoError(t, err)
+		assert.Equal(t, PurgeStats{Scanned: 7, Deleted: 4}, stats)
+
+		for _, key := range legacyKeys {
+			assert.True(t, server.Exists(key), key)
+		}
+	})
+
+	// miniredis, unlike Redis, shifts its SCAN cursor when keys are deleted,
+	// so the real run reads everything in one batch.
+	stats, err := PurgePasswordHashes(ctx, u.client, 100, false, log)
+	require.NoError(t, err)
+	assert.Equal(t, PurgeStats{Scanned: 7, Deleted: 4}, stats)
+
+	for _, key := range legacyKeys {
+		assert.False(t, server.Exists(key), key)
+	}
+
+	// Keys outside the user entries and entries in the current layout are
+	// left as they are.
+	assert.True(t, server.Exists("session:1"))
+	assert.True(t, server.Exists(usernameIndexKey))
+	got, err := u.GetUserByUsername(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got.ID)
+}
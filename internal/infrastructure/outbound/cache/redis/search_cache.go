@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"pinstack-user-service/internal/domain/models"
+	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/domain/ports/output/cache"
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+)
+
+// Search keys deliberately live outside user:* so the purge tool does not
+// scan them.
+const (
+	searchGenerationKey = "user_search:generation"
+	searchPageKeyPrefix = "user_search:page:"
+)
+
+type cachedSearchPage struct {
+	Users         []*cachedUser `json:"users"`
+	Total         int           `json:"total"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+}
+
+// SearchCache keeps the generation in a counter that BumpGeneration
+// increments. A missing counter, e.g. one deleted after a write was lost
+// during an outage, restarts from the current time in nanoseconds, which is
+// ahead of any value reached by increments since the previous start, so old
+// pages cannot become current again.
+type SearchCache struct {
+	client           *Client
+	pageTTL          time.Duration
+	ttlJitterPercent int
+	log              ports.Logger
+	metrics          ports.MetricsProvider
+}
+
+func NewSearchCache(client *Client, cfg config.Redis, log ports.Logger, metrics ports.MetricsProvider) *SearchCache {
+	return &SearchCache{
+		client:           client,
+		pageTTL:          cfg.SearchPageTTL,
+		ttlJitterPercent: cfg.TTLJitterPercent,
+		log:              log,
+		metrics:          metrics,
+	}
+}
+
+func (s *SearchCache) Generation(ctx context.Context) (int64, error) {
+	if s.pageTTL <= 0 {
+		return 0, custom_errors.ErrCacheMiss
+	}
+
+	raw, err := s.client.GetRaw(ctx, searchGenerationKey)
+	switch {
+	case err == nil:
+		generation, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid search generation %q: %w", raw, err)
+		}
+		return generation, nil
+	case errors.Is(err, custom_errors.ErrCacheMiss):
+		return s.bump(ctx)
+	case errors.Is(err, ErrUnavailable):
+		return 0, custom_errors.ErrCacheMiss
+	default:
+		return 0, fmt.Errorf("failed to get search generation: %w", err)
+	}
+}
+
+func (s *SearchCache) BumpGeneration(ctx context.Context) error {
+	if s.pageTTL <= 0 {
+		return nil
+	}
+
+	generation, err := s.bump(ctx)
+	if errors.Is(err, custom_errors.ErrCacheMiss) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("Search generation bumped", slog.Int64("generation", generation))
+	return nil
+}
+
+func (s *SearchCache) bump(ctx context.Context) (int64, error) {
+	generation, err := s.client.IncrFrom(ctx, searchGenerationKey, time.Now().UnixNano())
+	if errors.Is(err, ErrUnavailable) {
+		return 0, custom_errors.ErrCacheMiss
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to bump search generation: %w", err)
+	}
+	return generation, nil
+}
+
+func (s *SearchCache) GetPage(ctx context.Context, generation int64, key cache.SearchKey) (*cache.SearchPage, error) {
+	start := time.Now()
+
+	var page cachedSearchPage
+	err := s.client.Get(ctx, searchPageKey(generation, key), &page)
+
+	s.metrics.RecordCacheOperationDuration("get_search_page", time.Since(start))
+
+	if err != nil {
+		if isMiss(err) {
+			return nil, custom_errors.ErrCacheMiss
+		}
+		return nil, fmt.Errorf("failed to get search page: %w", err)
+	}
+
+	users := make([]*models.User, 0, len(page.Users))
+	for _, user := range page.Users {
+		users = append(users, user.toModel())
+	}
+	return &cache.SearchPage{
+		Users:         users,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+func (s *SearchCache) SetPage(ctx context.Context, generation int64, key cache.SearchKey, page *cache.SearchPage) error {
+	if s.pageTTL <= 0 {
+		return nil
+	}
+	start := time.Now()
+
+	users := make([]*cachedUser, 0, len(page.Users))
+	for _, user := range page.Users {
+		users = append(users, newCachedUser(user))
+	}
+	entry := &cachedSearchPage{
+		Users:         users,
+		Total:         page.Total,
+		NextPageToken: page.NextPageToken,
+	}
+
+	ttl := withJitter(s.pageTTL, s.ttlJitterPercent)
+	err := s.client.Set(ctx, searchPageKey(generation, key), entry, ttl)
+
+	s.metrics.RecordCacheOperationDuration("set_search_page", time.Since(start))
+
+	if err != nil && !errors.Is(err, ErrUnavailable) {
+		return fmt.Errorf("failed to cache search page: %w", err)
+	}
+	return nil
+}
+
+// searchPageKey hashes the query so arbitrary user input never ends up in a
+// key name and long queries do not make long keys.
+func searchPageKey(generation int64, key cache.SearchKey) string {
+	h := sha256.New()
+	for _, part := range []string{key.Query, strconv.Itoa(key.Offset), key.PageToken, strconv.Itoa(key.Limit)} {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return fmt.Sprintf("%s%d:%s", searchPageKeyPrefix, generation, hex.EncodeToString(h.Sum(nil)[:16]))
+}
@@ -0,0 +1,28 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+
+	"pinstack-user-service/internal/domain/ports/output/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchPageKey(t *testing.T) {
+	base := cache.SearchKey{Query: "ali", Offset: 0, Limit: 20}
+	key := searchPageKey(1, base)
+
+	assert.True(t, strings.HasPrefix(key, searchPageKeyPrefix+"1:"))
+	assert.Equal(t, key, searchPageKey(1, base))
+	assert.NotEqual(t, key, searchPageKey(2, base))
+
+	for _, other := range []cache.SearchKey{
+		{Query: "ali", Offset: 20, Limit: 20},
+		{Query: "ali", Offset: 0, Limit: 10},
+		{Query: "ali", PageToken: "token", Limit: 20},
+		{Query: "ali0", Limit: 20},
+	} {
+		assert.NotEqual(t, key, searchPageKey(1, other), "%+v", other)
+	}
+}
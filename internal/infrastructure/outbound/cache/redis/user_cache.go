@@ -5,33 +5,127 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"math/rand/v2"
 	"strconv"
+	"strings"
 	"time"
 
 	"pinstack-user-service/internal/domain/models"
 	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/infrastructure/config"
 
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
 )
 
+// User keys wrap the id, email or username in a hash tag, so in cluster mode
+// they spread over the slots like the users themselves. A user's email and
+// username keys therefore land in other slots than its id key; see
+// cluster.go for how writes spanning them are applied.
 const (
 	userCacheKeyPrefix         = "user:"
 	userEmailCacheKeyPrefix    = "user:email:"
 	userUsernameCacheKeyPrefix = "user:username:"
-	userCacheTTL               = 30 * time.Minute
+	defaultUserTTL             = 30 * time.Minute
 )
 
+// cachedUser is what gets stored under the user:{id} keys. It deliberately has
+// no password field so hashes never reach the shared Redis database.
+type cachedUser struct {
+	ID        int64   `json:"id"`
+	Username  string  `json:"username"`
+	Email     string  `json:"email"`
+	FullName  *string `json:"full_name,omitempty"`
+	Bio       *string `json:"bio,omitempty"`
+	AvatarURL *string `json:"avatar_url,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version,omitempty"`
+
+	// ExpiresAt mirrors the key TTL so reads can refresh the entry early.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// NotFound marks a tombstone: the lookup behind this key found no user.
+	// Tombstones live under the same keys as real entries, so SetUser
+	// replaces them as soon as the id, username or email is taken.
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+func newCachedUser(user *models.User) *cachedUser {
+	return &cachedUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Bio:       user.Bio,
+		AvatarURL: user.AvatarURL,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Version:   user.Version,
+	}
+}
+
+// unversioned reports whether a user entry was written before users carried a
+// version. Every row has a version, so such an entry cannot back an ETag and
+// is treated as a miss until it is reloaded.
+func (c *cachedUser) unversioned() bool {
+	return !c.NotFound && c.Version == 0
+}
+
+func (c *cachedUser) toModel() *models.User {
+	return &models.User{
+		ID:        c.ID,
+		Username:  c.Username,
+		Email:     c.Email,
+		FullName:  c.FullName,
+		Bio:       c.Bio,
+		AvatarURL: c.AvatarURL,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		Version:   c.Version,
+	}
+}
+
+// cachedPointer is what gets stored under the user:email:{email} and
+// user:username:{username} keys: just the id of the user entry they resolve
+// to, or a tombstone.
+type cachedPointer struct {
+	ID       int64 `json:"id,omitempty"`
+	NotFound bool  `json:"not_found,omitempty"`
+}
+
+// UserCache treats an open circuit breaker like an empty cache: reads miss,
+// and writes report success because the client already remembers their keys
+// for cleanup once Redis is back.
 type UserCache struct {
-	client  *Client
-	log     ports.Logger
-	metrics ports.MetricsProvider
+	client            *Client
+	userTTL           time.Duration
+	notFoundTTL       time.Duration
+	ttlJitterPercent  int
+	slidingExpiry     bool
+	earlyRefreshBeta  float64
+	earlyRefreshDelta time.Duration
+	log               ports.Logger
+	metrics           ports.MetricsProvider
 }
 
-func NewUserCache(client *Client, log ports.Logger, metrics ports.MetricsProvider) *UserCache {
+func NewUserCache(client *Client, cfg config.Redis, log ports.Logger, metrics ports.MetricsProvider) *UserCache {
+	userTTL := cfg.UserTTL
+	if userTTL <= 0 {
+		userTTL = defaultUserTTL
+	}
+
 	return &UserCache{
-		client:  client,
-		log:     log,
-		metrics: metrics,
+		client:            client,
+		userTTL:           userTTL,
+		notFoundTTL:       cfg.NotFoundTTL,
+		ttlJitterPercent:  cfg.TTLJitterPercent,
+		slidingExpiry:     cfg.SlidingExpiry,
+		earlyRefreshBeta:  cfg.EarlyRefreshBeta,
+		earlyRefreshDelta: cfg.EarlyRefreshDelta,
+		log:               log,
+		metrics:           metrics,
 	}
 }
 
@@ -39,14 +133,14 @@ func (u *UserCache) GetUserByID(ctx context.Context, userID int64) (*models.User
 	start := time.Now()
 	key := u.getUserKey(userID)
 
-	var user models.User
-	err := u.client.Get(ctx, key, &user)
+	var user cachedUser
+	err := u.get(ctx, key, &user)
 
 	duration := time.Since(start)
 	u.metrics.RecordCacheOperationDuration("get", duration)
 
 	if err != nil {
-		if errors.Is(err, custom_errors.ErrCacheMiss) {
+		if isMiss(err) {
 			u.metrics.IncrementCacheMisses()
 			u.log.Debug("User cache miss", slog.Int64("user_id", userID))
 			return nil, custom_errors.ErrCacheMiss
@@ -57,65 +151,152 @@ func (u *UserCache) GetUserByID(ctx context.Context, userID int64) (*models.User
 		return nil, fmt.Errorf("failed to get user from cache: %w", err)
 	}
 
+	if user.NotFound {
+		u.metrics.IncrementCacheNegativeHits()
+		u.log.Debug("User cache negative hit", slog.Int64("user_id", userID))
+		return nil, custom_errors.ErrUserNotFound
+	}
+
+	if user.unversioned() {
+		u.metrics.IncrementCacheMisses()
+		u.log.Debug("User cache entry has no version", slog.Int64("user_id", userID))
+		return nil, custom_errors.ErrCacheMiss
+	}
+
+	if u.refreshEarly(user.ExpiresAt) {
+		u.metrics.IncrementCacheMisses()
+		u.log.Debug("User cache early refresh", slog.Int64("user_id", userID))
+		return nil, custom_errors.ErrCacheMiss
+	}
+
 	u.metrics.IncrementCacheHits()
 	u.log.Debug("User cache hit", slog.Int64("user_id", userID))
-	return &user, nil
+	return user.toModel(), nil
 }
 
-func (u *UserCache) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+func (u *UserCache) GetUsersByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.User, error) {
 	start := time.Now()
-	key := u.getUserEmailKey(email)
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = u.getUserKey(id)
+	}
 
-	var user models.User
-	err := u.client.Get(ctx, key, &user)
+	entries := make([]*cachedUser, len(userIDs))
+	err := u.client.MGet(ctx, keys, func(i int) interface{} {
+		entries[i] = &cachedUser{}
+		return entries[i]
+	})
 
 	duration := time.Since(start)
-	u.metrics.RecordCacheOperationDuration("get", duration)
+	u.metrics.RecordCacheOperationDuration("mget", duration)
 
-	if err != nil {
-		if errors.Is(err, custom_errors.ErrCacheMiss) {
+	if errors.Is(err, ErrUnavailable) {
+		for range userIDs {
 			u.metrics.IncrementCacheMisses()
-			u.log.Debug("User email cache miss", slog.String("email", email))
-			return nil, custom_errors.ErrCacheMiss
 		}
-		u.log.Error("Failed to get user by email from cache",
-			slog.String("email", email),
+		return map[int64]*models.User{}, nil
+	}
+	if err != nil {
+		u.log.Error("Failed to get users from cache",
+			slog.Int("count", len(userIDs)),
 			slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to get user by email from cache: %w", err)
+		return nil, fmt.Errorf("failed to get users from cache: %w", err)
 	}
 
-	u.metrics.IncrementCacheHits()
-	u.log.Debug("User email cache hit", slog.String("email", email))
-	return &user, nil
+	users := make(map[int64]*models.User, len(userIDs))
+	for i, user := range entries {
+		if user == nil || user.ID != userIDs[i] || user.unversioned() {
+			u.metrics.IncrementCacheMisses()
+			continue
+		}
+		u.metrics.IncrementCacheHits()
+		users[user.ID] = user.toModel()
+	}
+
+	u.log.Debug("Users batch cache lookup",
+		slog.Int("requested", len(userIDs)),
+		slog.Int("hits", len(users)))
+	return users, nil
+}
+
+func (u *UserCache) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return u.getByPointer(ctx, u.getUserEmailKey(email), "email", slog.String("email", email),
+		func(user *cachedUser) bool { return user.Email == email })
 }
 
 func (u *UserCache) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return u.getByPointer(ctx, u.getUserUsernameKey(username), "username", slog.String("username", username),
+		func(user *cachedUser) bool { return user.Username == username })
+}
+
+// getByPointer resolves a pointer key to the user entry it names. The target
+// must still exist and still match the lookup (matches), otherwise the pointer
+// is stale and the read counts as a miss.
+func (u *UserCache) getByPointer(ctx context.Context, key, kind string, attr slog.Attr, matches func(*cachedUser) bool) (*models.User, error) {
 	start := time.Now()
-	key := u.getUserUsernameKey(username)
+	defer func() {
+		duration := time.Since(start)
+		u.metrics.RecordCacheOperationDuration("get", duration)
+	}()
 
-	var user models.User
-	err := u.client.Get(ctx, key, &user)
+	var pointer cachedPointer
+	if err := u.get(ctx, key, &pointer); err != nil {
+		if isMiss(err) {
+			u.metrics.IncrementCacheMisses()
+			u.log.Debug("User "+kind+" cache miss", attr)
+			return nil, custom_errors.ErrCacheMiss
+		}
+		u.log.Error("Failed to get user by "+kind+" from cache",
+			attr,
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to get user by %s from cache: %w", kind, err)
+	}
 
-	duration := time.Since(start)
-	u.metrics.RecordCacheOperationDuration("get", duration)
+	if pointer.NotFound {
+		u.metrics.IncrementCacheNegativeHits()
+		u.log.Debug("User "+kind+" cache negative hit", attr)
+		return nil, custom_errors.ErrUserNotFound
+	}
 
-	if err != nil {
-		if errors.Is(err, custom_errors.ErrCacheMiss) {
+	var user cachedUser
+	if err := u.get(ctx, u.getUserKey(pointer.ID), &user); err != nil {
+		if isMiss(err) {
 			u.metrics.IncrementCacheMisses()
-			u.log.Debug("User username cache miss", slog.String("username", username))
+			u.log.Debug("User "+kind+" cache pointer target missing", attr, slog.Int64("user_id", pointer.ID))
 			return nil, custom_errors.ErrCacheMiss
 		}
-		u.log.Error("Failed to get user by username from cache",
-			slog.String("username", username),
+		u.log.Error("Failed to get user by "+kind+" from cache",
+			attr,
+			slog.Int64("user_id", pointer.ID),
 			slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to get user by username from cache: %w", err)
+		return nil, fmt.Errorf("failed to get user by %s from cache: %w", kind, err)
+	}
+
+	if user.NotFound || user.unversioned() || !matches(&user) {
+		u.metrics.IncrementCacheMisses()
+		u.log.Debug("User "+kind+" cache pointer is stale", attr, slog.Int64("user_id", pointer.ID))
+		return nil, custom_errors.ErrCacheMiss
+	}
+
+	if u.refreshEarly(user.ExpiresAt) {
+		u.metrics.IncrementCacheMisses()
+		u.log.Debug("User "+kind+" cache early refresh", attr)
+		return nil, custom_errors.ErrCacheMiss
 	}
 
 	u.metrics.IncrementCacheHits()
-	u.log.Debug("User username cache hit", slog.String("username", username))
-	return &user, nil
+	u.log.Debug("User "+kind+" cache hit", attr)
+	return user.toModel(), nil
 }
 
+// SetUser caches user under its id key and points its email and username keys
+// at that id. The id key is versioned by the row version: a key that already
+// holds a newer version is left untouched, so a read that loaded the row
+// before a concurrent update cannot overwrite the fresher entry written by
+// that update. Pointers are only rewritten once the id key took the write.
+// They are replaced whatever they hold, because an email or username freed by
+// one user can be taken by another whose row version is lower, and a pointer
+// that lost a race resolves to a miss anyway.
 func (u *UserCache) SetUser(ctx context.Context, user *models.User) error {
 	start := time.Now()
 	defer func() {
@@ -127,71 +308,203 @@ func (u *UserCache) SetUser(ctx context.Context, user *models.User) error {
 		return fmt.Errorf("user cannot be nil")
 	}
 
-	idKey := u.getUserKey(user.ID)
-	if err := u.client.Set(ctx, idKey, user, userCacheTTL); err != nil {
-		u.log.Error("Failed to set user cache by ID",
+	ttl := u.withJitter(u.userTTL)
+	entry := newCachedUser(user)
+	entry.ExpiresAt = time.Now().Add(ttl)
+
+	stored, err := u.client.SetVersioned(ctx, u.getUserKey(user.ID), user.Version, entry, ttl)
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to set user cache",
 			slog.Int64("user_id", user.ID),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to set user cache by ID: %w", err)
+		return fmt.Errorf("failed to set user cache: %w", err)
 	}
-
-	emailKey := u.getUserEmailKey(user.Email)
-	if err := u.client.Set(ctx, emailKey, user, userCacheTTL); err != nil {
-		u.log.Error("Failed to set user cache by email",
-			slog.String("email", user.Email),
-			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to set user cache by email: %w", err)
+	if !stored {
+		u.log.Debug("Skipped cache write older than cached entry",
+			slog.Int64("user_id", user.ID),
+			slog.Int64("version", user.Version))
+		return nil
 	}
 
-	usernameKey := u.getUserUsernameKey(user.Username)
-	if err := u.client.Set(ctx, usernameKey, user, userCacheTTL); err != nil {
-		u.log.Error("Failed to set user cache by username",
-			slog.String("username", user.Username),
+	pointerKeys := []string{u.getUserEmailKey(user.Email), u.getUserUsernameKey(user.Username)}
+	err = u.client.ReplaceVersioned(ctx, pointerKeys, user.Version, &cachedPointer{ID: user.ID}, ttl)
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to set user cache pointers",
+			slog.Int64("user_id", user.ID),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to set user cache by username: %w", err)
+		return fmt.Errorf("failed to set user cache pointers: %w", err)
 	}
 
 	u.log.Debug("User cached successfully",
 		slog.Int64("user_id", user.ID),
 		slog.String("username", user.Username),
 		slog.String("email", user.Email),
-		slog.Duration("ttl", userCacheTTL))
+		slog.Duration("ttl", ttl))
 	return nil
 }
 
-func (u *UserCache) DeleteUser(ctx context.Context, user *models.User) error {
+// FillUser is SetUser: Redis is shared by every instance, so a read fill and a
+// write land in the same place, and versioning keeps either from overwriting
+// newer entries.
+func (u *UserCache) FillUser(ctx context.Context, user *models.User) error {
+	return u.SetUser(ctx, user)
+}
+
+// SetUserNotFoundByID writes a tombstone at version, which callers pass as
+// the deleted row's version plus one. It neither hides a user cached by a
+// later write nor lets an older read bring back the deleted user. With
+// negative caching disabled the key is simply removed.
+func (u *UserCache) SetUserNotFoundByID(ctx context.Context, userID int64, version int64) error {
+	key := u.getUserKey(userID)
+	if u.notFoundTTL <= 0 {
+		return u.client.Delete(ctx, key)
+	}
+
 	start := time.Now()
 	defer func() {
 		duration := time.Since(start)
-		u.metrics.RecordCacheOperationDuration("delete", duration)
+		u.metrics.RecordCacheOperationDuration("set_not_found", duration)
 	}()
 
-	if user == nil {
-		return fmt.Errorf("user cannot be nil")
+	ttl := u.withJitter(u.notFoundTTL)
+	stored, err := u.client.SetVersioned(ctx, key, version, &cachedUser{NotFound: true}, ttl)
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to set not found cache entry",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("failed to set not found cache entry: %w", err)
+	}
+	if !stored {
+		u.log.Debug("Skipped cache write older than cached entry",
+			slog.String("key", key),
+			slog.Int64("version", version))
+		return nil
 	}
 
-	idKey := u.getUserKey(user.ID)
-	if err := u.client.Delete(ctx, idKey); err != nil {
-		u.log.Error("Failed to delete user from cache by ID",
-			slog.Int64("user_id", user.ID),
+	u.log.Debug("Not found entry cached",
+		slog.String("key", key),
+		slog.Duration("ttl", ttl))
+	return nil
+}
+
+// ReleaseUsername drops the username pointer of a user who gave the username
+// up. Row versions belong to rows, not usernames, so a tombstone cannot be
+// ordered against the next owner and the key is removed instead; a later
+// lookup fills it from the database.
+func (u *UserCache) ReleaseUsername(ctx context.Context, username string) error {
+	return u.release(ctx, u.getUserUsernameKey(username))
+}
+
+// ReleaseEmail is ReleaseUsername for the email pointer.
+func (u *UserCache) ReleaseEmail(ctx context.Context, email string) error {
+	return u.release(ctx, u.getUserEmailKey(email))
+}
+
+func (u *UserCache) release(ctx context.Context, key string) error {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		u.metrics.RecordCacheOperationDuration("delete", duration)
+	}()
+
+	err := u.client.Delete(ctx, key)
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to release cache pointer",
+			slog.String("key", key),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to delete user from cache by ID: %w", err)
+		return fmt.Errorf("failed to release cache pointer: %w", err)
 	}
 
-	emailKey := u.getUserEmailKey(user.Email)
-	if err := u.client.Delete(ctx, emailKey); err != nil {
-		u.log.Error("Failed to delete user from cache by email",
-			slog.String("email", user.Email),
+	u.log.Debug("Cache pointer released", slog.String("key", key))
+	return nil
+}
+
+func (u *UserCache) FillUserNotFoundByID(ctx context.Context, userID int64) error {
+	return u.fillNotFound(ctx, u.getUserKey(userID), &cachedUser{NotFound: true})
+}
+
+func (u *UserCache) FillUserNotFoundByUsername(ctx context.Context, username string) error {
+	return u.fillNotFound(ctx, u.getUserUsernameKey(username), &cachedPointer{NotFound: true})
+}
+
+func (u *UserCache) FillUserNotFoundByEmail(ctx context.Context, email string) error {
+	return u.fillNotFound(ctx, u.getUserEmailKey(email), &cachedPointer{NotFound: true})
+}
+
+// fillNotFound writes a tombstone for a read that found no user, but only
+// into an empty key and at version zero. The read may have run on a lagging
+// replica, or before a concurrent create committed, so it must neither replace
+// an entry nor outrank a user cached later.
+func (u *UserCache) fillNotFound(ctx context.Context, key string, tombstone interface{}) error {
+	if u.notFoundTTL <= 0 {
+		return nil
+	}
+
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		u.metrics.RecordCacheOperationDuration("fill_not_found", duration)
+	}()
+
+	ttl := u.withJitter(u.notFoundTTL)
+	stored, err := u.client.SetVersionedIfAbsent(ctx, key, 0, tombstone, ttl)
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to set not found cache entry",
+			slog.String("key", key),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to delete user from cache by email: %w", err)
+		return fmt.Errorf("failed to set not found cache entry: %w", err)
+	}
+	if !stored {
+		u.log.Debug("Skipped not found cache entry, key is already cached", slog.String("key", key))
+		return nil
+	}
+
+	u.log.Debug("Not found entry cached",
+		slog.String("key", key),
+		slog.Duration("ttl", ttl))
+	return nil
+}
+
+// DeleteUser removes the id key and both pointer keys. A pointer that
+// outlives its entry only resolves to a miss.
+func (u *UserCache) DeleteUser(ctx context.Context, user *models.User) error {
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		u.metrics.RecordCacheOperationDuration("delete", duration)
+	}()
+
+	if user == nil {
+		return fmt.Errorf("user cannot be nil")
 	}
 
-	usernameKey := u.getUserUsernameKey(user.Username)
-	if err := u.client.Delete(ctx, usernameKey); err != nil {
-		u.log.Error("Failed to delete user from cache by username",
-			slog.String("username", user.Username),
+	err := u.client.Delete(ctx,
+		u.getUserKey(user.ID),
+		u.getUserEmailKey(user.Email),
+		u.getUserUsernameKey(user.Username))
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	if err != nil {
+		u.log.Error("Failed to delete user from cache",
+			slog.Int64("user_id", user.ID),
 			slog.String("error", err.Error()))
-		return fmt.Errorf("failed to delete user from cache by username: %w", err)
+		return fmt.Errorf("failed to delete user from cache: %w", err)
 	}
 
 	u.log.Debug("User deleted from cache",
@@ -201,25 +514,26 @@ func (u *UserCache) DeleteUser(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// DeleteUserByID reads the cached entry directly rather than through
+// GetUserByID, so an early-refresh miss cannot leave the pointer keys behind.
 func (u *UserCache) DeleteUserByID(ctx context.Context, userID int64) error {
 	start := time.Now()
-	user, err := u.GetUserByID(ctx, userID)
-	if err != nil {
-		if errors.Is(err, custom_errors.ErrCacheMiss) {
+	idKey := u.getUserKey(userID)
+
+	var user cachedUser
+	if err := u.client.Get(ctx, idKey, &user); err != nil {
+		if isMiss(err) {
 			u.log.Debug("User not in cache, nothing to delete", slog.Int64("user_id", userID))
 			return nil
 		}
 		u.log.Warn("Failed to get user for full cache deletion, deleting by ID only",
 			slog.Int64("user_id", userID),
 			slog.String("error", err.Error()))
+	} else if !user.NotFound {
+		return u.DeleteUser(ctx, user.toModel())
 	}
 
-	if user != nil {
-		return u.DeleteUser(ctx, user)
-	}
-
-	idKey := u.getUserKey(userID)
-	if err := u.client.Delete(ctx, idKey); err != nil {
+	if err := u.client.Delete(ctx, idKey); err != nil && !errors.Is(err, ErrUnavailable) {
 		u.log.Error("Failed to delete user from cache by ID",
 			slog.Int64("user_id", userID),
 			slog.String("error", err.Error()))
@@ -233,14 +547,84 @@ func (u *UserCache) DeleteUserByID(ctx context.Context, userID int64) error {
 	return nil
 }
 
+// get reads a single key. With sliding expiry, a key read in the second half
+// of its TTL gets a fresh TTL, so entries that keep being read stay cached
+// while one-off reads cost no extra write. Tombstones never slide: their TTL
+// is at most maxNotFoundTTL, and only keys above it are touched, which is
+// decided in the same script as the read. Batch reads go through MGET and do
+// not slide.
+func (u *UserCache) get(ctx context.Context, key string, dest interface{}) error {
+	if u.slidingExpiry {
+		return u.client.GetAndSlide(ctx, key, dest, u.withJitter(u.userTTL), u.maxNotFoundTTL(), u.userTTL/2)
+	}
+	return u.client.Get(ctx, key, dest)
+}
+
+// maxNotFoundTTL is the longest TTL a tombstone can be written with.
+func (u *UserCache) maxNotFoundTTL() time.Duration {
+	return u.notFoundTTL + u.notFoundTTL*time.Duration(u.ttlJitterPercent)/100
+}
+
+func (u *UserCache) withJitter(ttl time.Duration) time.Duration {
+	return withJitter(ttl, u.ttlJitterPercent)
+}
+
+// withJitter spreads ttl uniformly over ±percent so that entries written in
+// bulk do not all expire at the same moment.
+func withJitter(ttl time.Duration, percent int) time.Duration {
+	if percent <= 0 || ttl <= 0 {
+		return ttl
+	}
+	spread := int64(ttl) * int64(percent) / 100
+	if spread <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(2*spread+1)-spread)
+}
+
+// isMiss reports whether a client read error just means nothing can be served
+// from Redis right now.
+func isMiss(err error) bool {
+	return errors.Is(err, custom_errors.ErrCacheMiss) || errors.Is(err, ErrUnavailable)
+}
+
+// refreshEarly implements XFetch: it reports a miss ahead of expiry with a
+// probability that rises as expiresAt approaches, so one caller reloads the
+// entry while the others keep being served from cache.
+func (u *UserCache) refreshEarly(expiresAt time.Time) bool {
+	if u.slidingExpiry || u.earlyRefreshBeta <= 0 || expiresAt.IsZero() {
+		return false
+	}
+
+	gap := -float64(u.earlyRefreshDelta) * u.earlyRefreshBeta * math.Log(1-rand.Float64())
+	return time.Now().Add(time.Duration(gap)).After(expiresAt)
+}
+
 func (u *UserCache) getUserKey(userID int64) string {
-	return userCacheKeyPrefix + strconv.FormatInt(userID, 10)
+	return userCacheKeyPrefix + hashTag(strconv.FormatInt(userID, 10))
 }
 
 func (u *UserCache) getUserEmailKey(email string) string {
-	return userEmailCacheKeyPrefix + email
+	return userEmailCacheKeyPrefix + hashTag(email)
 }
 
 func (u *UserCache) getUserUsernameKey(username string) string {
-	return userUsernameCacheKeyPrefix + username
+	return userUsernameCacheKeyPrefix + hashTag(username)
+}
+
+// hashTag wraps part of a key in braces so Redis Cluster hashes only that
+// part.
+func hashTag(part string) string {
+	return "{" + part + "}"
+}
+
+// userKeyPart returns the hash-tagged part that follows prefix in key, and
+// whether key has that form. Keys written before user keys were hash-tagged,
+// such as user:42 or user:email:alice@example.com, do not.
+func userKeyPart(key, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok || len(rest) < 2 || rest[0] != '{' || rest[len(rest)-1] != '}' {
+		return "", false
+	}
+	return rest[1 : len(rest)-1], true
 }
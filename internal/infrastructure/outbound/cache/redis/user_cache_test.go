@@ -0,0 +1,219 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pinstack-user-service/internal/domain/models"
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client connected to a fresh in-memory Redis.
+func newTestClient(t *testing.T) (*Client, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client, err := NewClient(config.Redis{
+		Address: server.Host(),
+		Port:    server.Server().Addr().Port,
+	}, logger.New("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client, server
+}
+
+func newTestUserCache(t *testing.T, cfg config.Redis) (*UserCache, *miniredis.Miniredis) {
+	client, server := newTestClient(t)
+	return NewUserCache(client, cfg, logger.New("test"), prometheus.NewPrometheusMetricsProvider()), server
+}
+
+func TestUserCache_WithJitter(t *testing.T) {
+	u := &UserCache{ttlJitterPercent: 10}
+	base := 30 * time.Minute
+
+	seen := make(map[time.Duration]struct{})
+	for i := 0; i < 100; i++ {
+		ttl := u.withJitter(base)
+		assert.GreaterOrEqual(t, ttl, 27*time.Minute)
+		assert.LessOrEqual(t, ttl, 33*time.Minute)
+		seen[ttl] = struct{}{}
+	}
+	assert.Greater(t, len(seen), 1, "TTLs should be spread out")
+
+	u.ttlJitterPercent = 0
+	assert.Equal(t, base, u.withJitter(base))
+}
+
+func TestUserCache_FillUserNotFound(t *testing.T) {
+	ctx := context.Background()
+	u, _ := newTestUserCache(t, config.Redis{NotFoundTTL: time.Minute})
+	user := &models.User{ID: 1, Username: "alice", Email: "alice@example.com", UpdatedAt: time.Now(), Version: 1}
+
+	t.Run("does not replace a cached user", func(t *testing.T) {
+		require.NoError(t, u.SetUser(ctx, user))
+		require.NoError(t, u.FillUserNotFoundByID(ctx, 1))
+		require.NoError(t, u.FillUserNotFoundByUsername(ctx, "alice"))
+
+		got, err := u.GetUserByUsername(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), got.ID)
+	})
+
+	t.Run("loses to any later write", func(t *testing.T) {
+		require.NoError(t, u.FillUserNotFoundByEmail(ctx, "bob@example.com"))
+		_, err := u.GetUserByEmail(ctx, "bob@example.com")
+		assert.ErrorIs(t, err, custom_errors.ErrUserNotFound)
+
+		// However old the created row's version.
+		bob := &models.User{ID: 2, Username: "bob", Email: "bob@example.com", UpdatedAt: time.Unix(1, 0), Version: 1}
+		require.NoError(t, u.SetUser(ctx, bob))
+		got, err := u.GetUserByEmail(ctx, "bob@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got.ID)
+	})
+}
+
+func TestUserCache_UnversionedEntryIsMiss(t *testing.T) {
+	ctx := context.Background()
+	u, _ := newTestUserCache(t, config.Redis{})
+
+	// Written before users were versioned.
+	legacy := &models.User{ID: 1, Username: "alice", Email: "alice@example.com", UpdatedAt: time.Now()}
+	require.NoError(t, u.SetUser(ctx, legacy))
+
+	_, err := u.GetUserByID(ctx, 1)
+	assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+	_, err = u.GetUserByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+	users, err := u.GetUsersByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	// Reloading the row replaces it.
+	legacy.Version = 1
+	require.NoError(t, u.SetUser(ctx, legacy))
+	got, err := u.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.Version)
+}
+
+func TestUserCache_SlidingExpiry(t *testing.T) {
+	ctx := context.Background()
+	u, server := newTestUserCache(t, config.Redis{
+		UserTTL:       30 * time.Minute,
+		NotFoundTTL:   time.Minute,
+		SlidingExpiry: true,
+	})
+	alice := &models.User{ID: 1, Username: "alice", Email: "alice@example.com", UpdatedAt: time.Now(), Version: 1}
+	require.NoError(t, u.SetUser(ctx, alice))
+	key := u.getUserKey(1)
+
+	server.FastForward(5 * time.Minute)
+	_, err := u.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Minute, server.TTL(key), "a read in the first half of the TTL does not slide")
+
+	server.FastForward(15 * time.Minute)
+	_, err = u.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, server.TTL(key), "a read in the second half of the TTL slides")
+
+	require.NoError(t, u.FillUserNotFoundByID(ctx, 2))
+	_, err = u.GetUserByID(ctx, 2)
+	assert.ErrorIs(t, err, custom_errors.ErrUserNotFound)
+	assert.Equal(t, time.Minute, server.TTL(u.getUserKey(2)), "tombstones never slide")
+}
+
+func TestClient_SetVersionedMulti(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	keys := []string{"user:{1}", "user:email:{a@example.com}", "user:username:{a}"}
+
+	stored, err := client.SetVersionedMulti(ctx, keys, []interface{}{"v2", "v2", "v2"}, 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored)
+
+	stored, err = client.SetVersionedMulti(ctx, keys, []interface{}{"v1", "v1", "v1"}, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored, "an older version is rejected for every key")
+	for _, key := range keys {
+		raw, err := server.Get(key)
+		require.NoError(t, err)
+		version, _ := splitVersion(raw)
+		assert.Equal(t, int64(2), version, key)
+	}
+
+	stored, err = client.SetVersionedMulti(ctx, keys, []interface{}{"v3", "v3", "v3"}, 3, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored)
+}
+
+func TestUserCache_GetByPointer(t *testing.T) {
+	ctx := context.Background()
+	u, server := newTestUserCache(t, config.Redis{})
+	alice := &models.User{ID: 1, Username: "alice", Email: "alice@example.com", UpdatedAt: time.Unix(100, 0), Version: 1}
+	require.NoError(t, u.SetUser(ctx, alice))
+
+	got, err := u.GetUserByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.ID)
+
+	t.Run("target missing", func(t *testing.T) {
+		server.Del(u.getUserKey(1))
+		_, err := u.GetUserByEmail(ctx, "alice@example.com")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+		_, err = u.GetUserByUsername(ctx, "alice")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+	})
+
+	t.Run("target no longer matches", func(t *testing.T) {
+		// A rename writes pointers for the new email and username only; the
+		// old ones still point at the user.
+		renamed := *alice
+		renamed.Email = "alice@example.org"
+		renamed.Username = "alice2"
+		renamed.UpdatedAt = time.Unix(200, 0)
+		renamed.Version = 2
+		require.NoError(t, u.SetUser(ctx, &renamed))
+
+		_, err := u.GetUserByEmail(ctx, "alice@example.com")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+		_, err = u.GetUserByUsername(ctx, "alice")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+
+		got, err := u.GetUserByUsername(ctx, "alice2")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.org", got.Email)
+	})
+
+	t.Run("older write rejected", func(t *testing.T) {
+		// Still at version 1, read before the rename.
+		stale := *alice
+		require.NoError(t, u.SetUser(ctx, &stale))
+
+		got, err := u.GetUserByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "alice2", got.Username)
+		_, err = u.GetUserByEmail(ctx, "alice@example.com")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss, "the stale pointer was not revived")
+	})
+
+	t.Run("released username taken by a lower version", func(t *testing.T) {
+		require.NoError(t, u.ReleaseUsername(ctx, "alice2"))
+		_, err := u.GetUserByUsername(ctx, "alice2")
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+
+		// Row versions are per user, so the new owner's can be lower than
+		// the old owner's.
+		require.NoError(t, u.SetUser(ctx, &models.User{ID: 2, Username: "alice2", Email: "bob@example.com", Version: 1}))
+		got, err := u.GetUserByUsername(ctx, "alice2")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got.ID)
+	})
+}
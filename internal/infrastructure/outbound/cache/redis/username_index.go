@@ -0,0 +1,146 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+)
+
+const usernameIndexKey = "user:usernames"
+
+// usernameMemberSeparator splits the lowercased sort key from the original
+// username inside a sorted-set member, e.g. "alice\x00Alice".
+const usernameMemberSeparator = "\x00"
+
+// The build state lives in the set itself as members with an empty sort key,
+// which sort before every username, so it disappears together with the
+// usernames whenever the key is dropped.
+const (
+	usernameIndexBuilding = usernameMemberSeparator + "building"
+	usernameIndexComplete = usernameMemberSeparator + "complete"
+)
+
+var errUsernameIndexDropped = errors.New("username index was dropped during the build")
+
+// UsernameIndex stores usernames in a sorted set with equal scores so that
+// ZRANGEBYLEX returns them in byte order, which makes prefix lookups cheap.
+// Lookups are only served once a build marked the index complete. Writes
+// skipped while Redis is unavailable get the whole index dropped once it is
+// back, markers included, after which lookups fall back to the database until
+// the index is built again.
+type UsernameIndex struct {
+	client  *Client
+	log     ports.Logger
+	metrics ports.MetricsProvider
+}
+
+func NewUsernameIndex(client *Client, log ports.Logger, metrics ports.MetricsProvider) *UsernameIndex {
+	return &UsernameIndex{
+		client:  client,
+		log:     log,
+		metrics: metrics,
+	}
+}
+
+func (i *UsernameIndex) AddUsernames(ctx context.Context, usernames ...string) error {
+	start := time.Now()
+	defer func() {
+		i.metrics.RecordCacheOperationDuration("zadd", time.Since(start))
+	}()
+
+	members := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		members = append(members, usernameMember(username))
+	}
+
+	if err := i.client.ZAddLex(ctx, usernameIndexKey, members...); err != nil && !errors.Is(err, ErrUnavailable) {
+		return fmt.Errorf("failed to add usernames to index: %w", err)
+	}
+
+	i.log.Debug("Usernames added to index", slog.Int("count", len(usernames)))
+	return nil
+}
+
+func (i *UsernameIndex) RemoveUsername(ctx context.Context, username string) error {
+	start := time.Now()
+	defer func() {
+		i.metrics.RecordCacheOperationDuration("zrem", time.Since(start))
+	}()
+
+	if err := i.client.ZRem(ctx, usernameIndexKey, usernameMember(username)); err != nil && !errors.Is(err, ErrUnavailable) {
+		return fmt.Errorf("failed to remove username from index: %w", err)
+	}
+
+	i.log.Debug("Username removed from index", slog.String("username", username))
+	return nil
+}
+
+func (i *UsernameIndex) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	start := time.Now()
+	lowered := strings.ToLower(prefix)
+
+	// 0xff never occurs in UTF-8, so it sorts after every member with this prefix.
+	members, complete, err := i.client.ZRangeByLexIfMember(ctx, usernameIndexKey, usernameIndexComplete,
+		"["+lowered, "["+lowered+"\xff", limit)
+
+	i.metrics.RecordCacheOperationDuration("zrangebylex", time.Since(start))
+
+	if errors.Is(err, ErrUnavailable) {
+		return nil, custom_errors.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to autocomplete usernames from index: %w", err)
+	}
+	if !complete {
+		i.log.Debug("Username index is not complete", slog.String("prefix", prefix))
+		return nil, custom_errors.ErrCacheMiss
+	}
+
+	usernames := make([]string, 0, len(members))
+	for _, member := range members {
+		key, username, ok := strings.Cut(member, usernameMemberSeparator)
+		if !ok || key == "" {
+			continue
+		}
+		usernames = append(usernames, username)
+	}
+
+	i.log.Debug("Username index hit",
+		slog.String("prefix", prefix),
+		slog.Int("count", len(usernames)))
+	return usernames, nil
+}
+
+func (i *UsernameIndex) BeginBuild(ctx context.Context) (bool, error) {
+	begun, err := i.client.ZAddUnlessMember(ctx, usernameIndexKey, usernameIndexComplete, usernameIndexBuilding)
+	if errors.Is(err, ErrUnavailable) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to begin username index build: %w", err)
+	}
+	return begun, nil
+}
+
+func (i *UsernameIndex) MarkComplete(ctx context.Context) error {
+	marked, err := i.client.ZReplaceMember(ctx, usernameIndexKey, usernameIndexBuilding, usernameIndexComplete)
+	if err != nil {
+		return fmt.Errorf("failed to mark username index complete: %w", err)
+	}
+	if !marked {
+		return errUsernameIndexDropped
+	}
+	i.log.Debug("Username index marked complete")
+	return nil
+}
+
+func usernameMember(username string) string {
+	return strings.ToLower(username) + usernameMemberSeparator + username
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+
+	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsernameIndex_ServedOnlyWhenComplete(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	index := NewUsernameIndex(client, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+
+	require.NoError(t, index.AddUsernames(ctx, "alice", "Alison"))
+	_, err := index.AutocompleteUsernames(ctx, "al", 10)
+	assert.ErrorIs(t, err, custom_errors.ErrCacheMiss, "a partial index is not served")
+
+	begun, err := index.BeginBuild(ctx)
+	require.NoError(t, err)
+	require.True(t, begun)
+	require.NoError(t, index.AddUsernames(ctx, "bob"))
+	require.NoError(t, index.MarkComplete(ctx))
+
+	usernames, err := index.AutocompleteUsernames(ctx, "AL", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "Alison"}, usernames)
+
+	usernames, err = index.AutocompleteUsernames(ctx, "zz", 10)
+	require.NoError(t, err, "a complete index answers for missing prefixes too")
+	assert.Empty(t, usernames)
+
+	begun, err = index.BeginBuild(ctx)
+	require.NoError(t, err)
+	assert.False(t, begun, "a complete index needs no build")
+
+	t.Run("dropped during the build", func(t *testing.T) {
+		server.Del(usernameIndexKey)
+
+		begun, err := index.BeginBuild(ctx)
+		require.NoError(t, err)
+		require.True(t, begun)
+		require.NoError(t, index.AddUsernames(ctx, "alice"))
+
+		server.Del(usernameIndexKey)
+		require.NoError(t, index.AddUsernames(ctx, "bob"))
+		assert.ErrorIs(t, index.MarkComplete(ctx), errUsernameIndexDropped)
+
+		_, err = index.AutocompleteUsernames(ctx, "b", 10)
+		assert.ErrorIs(t, err, custom_errors.ErrCacheMiss)
+	})
+}
@@ -0,0 +1,153 @@
+package argon2id
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidHash         = errors.New("invalid password hash format")
+	ErrIncompatibleVersion = errors.New("incompatible argon2 version")
+)
+
+type params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	keyLength   uint32
+}
+
+// Hasher produces argon2id hashes in the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$key) and verifies argon2id hashes,
+// legacy bcrypt hashes and the plaintext passwords stored before hashing was
+// introduced, so existing users keep working until rehashed.
+type Hasher struct {
+	params     params
+	saltLength uint32
+}
+
+func NewPasswordHasher(cfg config.Hasher) *Hasher {
+	return &Hasher{
+		params: params{
+			memory:      cfg.Memory,
+			iterations:  cfg.Iterations,
+			parallelism: cfg.Parallelism,
+			keyLength:   cfg.KeyLength,
+		},
+		saltLength: cfg.SaltLength,
+	}
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.iterations, h.params.memory, h.params.parallelism, h.params.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.memory,
+		h.params.iterations,
+		h.params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Hasher) Verify(password, hash string) (bool, error) {
+	if hash == "" {
+		return false, ErrInvalidHash
+	}
+	if isPlaintext(hash) {
+		// Compare digests so the comparison does not leak the stored
+		// password's length either.
+		stored, given := sha256.Sum256([]byte(hash)), sha256.Sum256([]byte(password))
+		return subtle.ConstantTimeCompare(stored[:], given[:]) == 1, nil
+	}
+	if isBcrypt(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		switch {
+		case err == nil:
+			return true, nil
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to verify bcrypt hash: %w", err)
+		}
+	}
+
+	p, salt, key, err := decodeHash(hash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if isBcrypt(hash) {
+		return true
+	}
+
+	p, salt, _, err := decodeHash(hash)
+	if err != nil {
+		return true
+	}
+	return p != h.params || uint32(len(salt)) != h.saltLength
+}
+
+// isPlaintext reports whether a stored value predates password hashing. Every
+// hash format this package reads starts with '$'.
+func isPlaintext(hash string) bool {
+	return !strings.HasPrefix(hash, "$")
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func decodeHash(hash string) (params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params{}, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return params{}, nil, nil, ErrIncompatibleVersion
+	}
+
+	var p params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return params{}, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params{}, nil, nil, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params{}, nil, nil, ErrInvalidHash
+	}
+	p.keyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -0,0 +1,98 @@
+package argon2id_test
+
+import (
+	"testing"
+
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/outbound/hasher/argon2id"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestHasher(iterations uint32) *argon2id.Hasher {
+	return argon2id.NewPasswordHasher(config.Hasher{
+		Memory:      1024,
+		Iterations:  iterations,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+}
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	hasher := newTestHasher(1)
+
+	hash, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.NotEqual(t, "password123", hash)
+	assert.Contains(t, hash, "$argon2id$")
+
+	ok, err := hasher.Verify("password123", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("wrongpassword", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	otherHash, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+}
+
+func TestHasher_VerifyInvalidHash(t *testing.T) {
+	hasher := newTestHasher(1)
+
+	for _, hash := range []string{"", "$argon2id$v=19$garbage", "$unknown$hash"} {
+		ok, err := hasher.Verify("password123", hash)
+		assert.ErrorIs(t, err, argon2id.ErrInvalidHash, hash)
+		assert.False(t, ok)
+	}
+}
+
+func TestHasher_LegacyPlaintext(t *testing.T) {
+	hasher := newTestHasher(1)
+
+	ok, err := hasher.Verify("password123", "password123")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("password12", "password123")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.True(t, hasher.NeedsRehash("password123"))
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	hasher := newTestHasher(1)
+	stronger := newTestHasher(2)
+
+	hash, err := hasher.Hash("password123")
+	require.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(hash))
+	assert.True(t, stronger.NeedsRehash(hash))
+
+	ok, err := stronger.Verify("password123", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHasher_LegacyBcrypt(t *testing.T) {
+	hasher := newTestHasher(1)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok, err := hasher.Verify("password123", string(legacy))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("wrongpassword", string(legacy))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.True(t, hasher.NeedsRehash(string(legacy)))
+}
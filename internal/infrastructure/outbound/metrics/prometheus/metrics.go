@@ -26,25 +26,66 @@ var (
 	DatabaseQueriesTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "database_queries_total",
-			Help: "Total number of database queries executed",
+			Help: "Total number of database queries executed, by pool",
 		},
-		[]string{"query_type", "success"},
+		[]string{"pool", "query_type", "success"},
 	)
 
 	DatabaseQueryDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "database_query_duration_seconds",
-			Help:    "Duration of database queries in seconds",
+			Help:    "Duration of database queries in seconds, by pool",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"query_type"},
+		[]string{"pool", "query_type"},
 	)
 
-	CacheHitsTotal = promauto.NewCounter(
+	DatabaseReplicaLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "database_replica_lag_seconds",
+			Help: "Replication lag of each Postgres read replica at its last check",
+		},
+		[]string{"pool"},
+	)
+
+	DatabasePoolConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "database_pool_connections",
+			Help: "Connections in each Postgres pool, by state (acquired, idle, total or max)",
+		},
+		[]string{"pool", "state"},
+	)
+
+	DatabasePoolAcquiresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "database_pool_acquires_total",
+			Help: "Total number of connections acquired from each Postgres pool",
+		},
+		[]string{"pool"},
+	)
+
+	DatabasePoolWaitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "database_pool_waits_total",
+			Help: "Total number of acquires that waited for a connection because the pool had none idle",
+		},
+		[]string{"pool"},
+	)
+
+	DatabasePoolWaitSeconds = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "database_pool_wait_seconds_total",
+			Help: "Total time acquires spent waiting for a connection because the pool had none idle",
+		},
+		[]string{"pool"},
+	)
+
+	CacheHitsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "cache_hits_total",
-			Help: "Total number of cache hits",
+			Help: "Total number of cache hits, by whether a user or a not-found entry was served",
 		},
+		[]string{"entry"},
 	)
 
 	CacheMissesTotal = promauto.NewCounter(
@@ -63,6 +104,29 @@ var (
 		[]string{"operation"},
 	)
 
+	CacheCoalescedRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_coalesced_requests_total",
+			Help: "Total number of cache misses served by another request's in-flight backend load",
+		},
+		[]string{"operation"},
+	)
+
+	CacheTierLookupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_tier_lookups_total",
+			Help: "Total number of lookups per cache tier, by result (hit or miss)",
+		},
+		[]string{"tier", "result"},
+	)
+
+	CacheAvailable = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cache_available",
+			Help: "Whether the Redis cache is reachable (1) or bypassed by the circuit breaker (0)",
+		},
+	)
+
 	UserOperationsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "user_operations_total",
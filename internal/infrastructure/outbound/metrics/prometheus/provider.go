@@ -21,16 +21,37 @@ func (p *PrometheusMetricsProvider) RecordGRPCRequestDuration(method, status str
 	GRPCRequestDuration.WithLabelValues(method, status).Observe(duration.Seconds())
 }
 
-func (p *PrometheusMetricsProvider) IncrementDatabaseQueries(queryType string, success bool) {
-	DatabaseQueriesTotal.WithLabelValues(queryType, strconv.FormatBool(success)).Inc()
+func (p *PrometheusMetricsProvider) IncrementDatabaseQueries(pool, queryType string, success bool) {
+	DatabaseQueriesTotal.WithLabelValues(pool, queryType, strconv.FormatBool(success)).Inc()
 }
 
-func (p *PrometheusMetricsProvider) RecordDatabaseQueryDuration(queryType string, duration time.Duration) {
-	DatabaseQueryDuration.WithLabelValues(queryType).Observe(duration.Seconds())
+func (p *PrometheusMetricsProvider) RecordDatabaseQueryDuration(pool, queryType string, duration time.Duration) {
+	DatabaseQueryDuration.WithLabelValues(pool, queryType).Observe(duration.Seconds())
+}
+
+func (p *PrometheusMetricsProvider) SetDatabaseReplicaLag(pool string, lag time.Duration) {
+	DatabaseReplicaLag.WithLabelValues(pool).Set(lag.Seconds())
+}
+
+func (p *PrometheusMetricsProvider) SetDatabasePoolConnections(pool string, acquired, idle, total, max int) {
+	DatabasePoolConnections.WithLabelValues(pool, "acquired").Set(float64(acquired))
+	DatabasePoolConnections.WithLabelValues(pool, "idle").Set(float64(idle))
+	DatabasePoolConnections.WithLabelValues(pool, "total").Set(float64(total))
+	DatabasePoolConnections.WithLabelValues(pool, "max").Set(float64(max))
+}
+
+func (p *PrometheusMetricsProvider) AddDatabasePoolAcquires(pool string, acquires, waits int64, waitTime time.Duration) {
+	DatabasePoolAcquiresTotal.WithLabelValues(pool).Add(float64(acquires))
+	DatabasePoolWaitsTotal.WithLabelValues(pool).Add(float64(waits))
+	DatabasePoolWaitSeconds.WithLabelValues(pool).Add(waitTime.Seconds())
 }
 
 func (p *PrometheusMetricsProvider) IncrementCacheHits() {
-	CacheHitsTotal.Inc()
+	CacheHitsTotal.WithLabelValues("positive").Inc()
+}
+
+func (p *PrometheusMetricsProvider) IncrementCacheNegativeHits() {
+	CacheHitsTotal.WithLabelValues("negative").Inc()
 }
 
 func (p *PrometheusMetricsProvider) IncrementCacheMisses() {
@@ -41,6 +62,26 @@ func (p *PrometheusMetricsProvider) RecordCacheOperationDuration(operation strin
 	CacheOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
 }
 
+func (p *PrometheusMetricsProvider) IncrementCacheCoalescedRequests(operation string) {
+	CacheCoalescedRequestsTotal.WithLabelValues(operation).Inc()
+}
+
+func (p *PrometheusMetricsProvider) IncrementCacheTierHits(tier string) {
+	CacheTierLookupsTotal.WithLabelValues(tier, "hit").Inc()
+}
+
+func (p *PrometheusMetricsProvider) IncrementCacheTierMisses(tier string) {
+	CacheTierLookupsTotal.WithLabelValues(tier, "miss").Inc()
+}
+
+func (p *PrometheusMetricsProvider) SetCacheAvailable(available bool) {
+	if available {
+		CacheAvailable.Set(1)
+	} else {
+		CacheAvailable.Set(0)
+	}
+}
+
 func (p *PrometheusMetricsProvider) IncrementUserOperations(operation string, success bool) {
 	UserOperationsTotal.WithLabelValues(operation, strconv.FormatBool(success)).Inc()
 }
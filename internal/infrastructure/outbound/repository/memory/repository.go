@@ -46,10 +46,11 @@ func (r *Repository) Create(ctx context.Context, user *models.User) (*models.Use
 	user.ID = r.nextID
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 	r.users[user.ID] = user
 	r.nextID++
 
-	return user, nil
+	return withoutPassword(user), nil
 }
 
 func (r *Repository) GetByID(ctx context.Context, id int64) (*models.User, error) {
@@ -61,7 +62,21 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*models.User, error
 		return nil, custom_errors.ErrUserNotFound
 	}
 
-	return user, nil
+	return withoutPassword(user), nil
+}
+
+func (r *Repository) GetByIDs(ctx context.Context, ids []int64) ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, exists := r.users[id]; exists {
+			users = append(users, withoutPassword(user))
+		}
+	}
+
+	return users, nil
 }
 
 func (r *Repository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
@@ -70,7 +85,7 @@ func (r *Repository) GetByUsername(ctx context.Context, username string) (*model
 
 	for _, user := range r.users {
 		if user.Username == username {
-			return user, nil
+			return withoutPassword(user), nil
 		}
 	}
 
@@ -83,14 +98,61 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User
 
 	for _, user := range r.users {
 		if user.Email == email {
-			return user, nil
+			return withoutPassword(user), nil
+		}
+	}
+
+	return nil, custom_errors.ErrUserNotFound
+}
+
+func (r *Repository) GetCredentialsByID(ctx context.Context, id int64) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return nil, custom_errors.ErrUserNotFound
+	}
+
+	credentials := *user
+	return &credentials, nil
+}
+
+func (r *Repository) GetCredentialsByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			credentials := *user
+			return &credentials, nil
+		}
+	}
+
+	return nil, custom_errors.ErrUserNotFound
+}
+
+func (r *Repository) GetCredentialsByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			credentials := *user
+			return &credentials, nil
 		}
 	}
 
 	return nil, custom_errors.ErrUserNotFound
 }
 
-func (r *Repository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+// GetCredentialsByIDForUpdate relies on TxManager serializing transactions
+// for its locking.
+func (r *Repository) GetCredentialsByIDForUpdate(ctx context.Context, id int64) (*models.User, error) {
+	return r.GetCredentialsByID(ctx, id)
+}
+
+func (r *Repository) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -98,23 +160,41 @@ func (r *Repository) Update(ctx context.Context, user *models.User) (*models.Use
 	if !exists {
 		return nil, custom_errors.ErrUserNotFound
 	}
+	if user.Version > 0 && user.Version != existingUser.Version {
+		return nil, models.ErrVersionConflict
+	}
 
 	for _, u := range r.users {
 		if u.ID != user.ID {
-			if u.Username == user.Username && u.Username != existingUser.Username {
+			if fields.Has(models.UserFieldUsername) && u.Username == user.Username {
 				return nil, custom_errors.ErrUsernameExists
 			}
-			if u.Email == user.Email && u.Email != existingUser.Email {
+			if fields.Has(models.UserFieldEmail) && u.Email == user.Email {
 				return nil, custom_errors.ErrEmailExists
 			}
 		}
 	}
 
-	user.CreatedAt = existingUser.CreatedAt
-	user.UpdatedAt = time.Now()
-	r.users[user.ID] = user
+	// Only the named fields change, matching the postgres repository; the
+	// password and avatar have their own update paths.
+	updated := *existingUser
+	if fields.Has(models.UserFieldUsername) {
+		updated.Username = user.Username
+	}
+	if fields.Has(models.UserFieldEmail) {
+		updated.Email = user.Email
+	}
+	if fields.Has(models.UserFieldFullName) {
+		updated.FullName = user.FullName
+	}
+	if fields.Has(models.UserFieldBio) {
+		updated.Bio = user.Bio
+	}
+	updated.UpdatedAt = time.Now()
+	updated.Version = existingUser.Version + 1
+	r.users[user.ID] = &updated
 
-	return user, nil
+	return withoutPassword(&updated), nil
 }
 
 func (r *Repository) Delete(ctx context.Context, id int64) error {
@@ -133,22 +213,12 @@ func (r *Repository) Search(ctx context.Context, searchQuery string, offset, pag
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	var results []*models.User
-	for _, user := range r.users {
-		if searchQuery == "" ||
-			contains(user.Username, searchQuery) ||
-			contains(user.Email, searchQuery) ||
-			(user.FullName != nil && contains(*user.FullName, searchQuery)) ||
-			(user.Bio != nil && contains(*user.Bio, searchQuery)) {
-			results = append(results, user)
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Username < results[j].Username
+	matches := r.searchMatches(searchQuery)
+	sort.Slice(matches, func(i, j int) bool {
+		return rankLess(matches[i], matches[j])
 	})
 
-	total := len(results)
+	total := len(matches)
 	if offset >= total {
 		return []*models.User{}, total, nil
 	}
@@ -158,7 +228,104 @@ func (r *Repository) Search(ctx context.Context, searchQuery string, offset, pag
 		end = total
 	}
 
-	return results[offset:end], total, nil
+	users := make([]*models.User, 0, end-offset)
+	for _, match := range matches[offset:end] {
+		users = append(users, match.user)
+	}
+	return users, total, nil
+}
+
+func (r *Repository) SearchAfter(ctx context.Context, searchQuery string, cursor *models.SearchCursor, pageSize int) ([]*models.SearchHit, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.searchMatches(searchQuery)
+	sort.Slice(matches, func(i, j int) bool {
+		return rankLess(matches[i], matches[j])
+	})
+
+	// Like the postgres repository, later pages report the total carried in
+	// the cursor instead of recounting.
+	total := len(matches)
+	start := 0
+	if cursor != nil {
+		total = cursor.Total
+		after := searchMatch{user: &models.User{ID: cursor.ID, Username: cursor.Username}, rank: cursor.Rank}
+		start = sort.Search(len(matches), func(i int) bool {
+			return rankLess(after, matches[i])
+		})
+	}
+
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	hits := make([]*models.SearchHit, 0, end-start)
+	for _, match := range matches[start:end] {
+		hits = append(hits, &models.SearchHit{
+			User:   match.user,
+			Cursor: models.SearchCursor{Rank: match.rank, Username: match.user.Username, ID: match.user.ID, Total: total},
+		})
+	}
+	return hits, total, nil
+}
+
+// searchMatches returns the users matching searchQuery in no particular
+// order; the caller must hold r.mu.
+func (r *Repository) searchMatches(searchQuery string) []searchMatch {
+	matches := make([]searchMatch, 0)
+	for _, user := range r.users {
+		if rank, ok := searchRank(user, searchQuery); ok {
+			matches = append(matches, searchMatch{user: withoutPassword(user), rank: rank})
+		}
+	}
+	return matches
+}
+
+func (r *Repository) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lowered := strings.ToLower(prefix)
+	usernames := make([]string, 0, limit)
+	for _, user := range r.users {
+		if strings.HasPrefix(strings.ToLower(user.Username), lowered) {
+			usernames = append(usernames, user.Username)
+		}
+	}
+
+	sort.Slice(usernames, func(i, j int) bool {
+		return strings.ToLower(usernames[i]) < strings.ToLower(usernames[j])
+	})
+	if len(usernames) > limit {
+		usernames = usernames[:limit]
+	}
+	return usernames, nil
+}
+
+func (r *Repository) ListUsernames(ctx context.Context, afterID int64, limit int) ([]string, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int64, 0, len(r.users))
+	for id := range r.users {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	usernames := make([]string, 0, len(ids))
+	lastID := afterID
+	for _, id := range ids {
+		usernames = append(usernames, r.users[id].Username)
+		lastID = id
+	}
+	return usernames, lastID, nil
 }
 
 func (r *Repository) UpdatePassword(ctx context.Context, id int64, password string) error {
@@ -172,6 +339,7 @@ func (r *Repository) UpdatePassword(ctx context.Context, id int64, password stri
 
 	user.Password = password
 	user.UpdatedAt = time.Now()
+	user.Version++
 	return nil
 }
 
@@ -186,9 +354,14 @@ func (r *Repository) UpdateAvatar(ctx context.Context, id int64, avatarURL strin
 
 	user.AvatarURL = &avatarURL
 	user.UpdatedAt = time.Now()
+	user.Version++
 	return nil
 }
 
-func contains(s, substr string) bool {
-	return len(substr) == 0 || strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+// withoutPassword returns a copy of user that is safe to hand out from read
+// paths; only the GetCredentialsBy* methods expose the stored hash.
+func withoutPassword(user *models.User) *models.User {
+	public := *user
+	public.Password = ""
+	return &public
 }
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+
+	"pinstack-user-service/internal/domain/models"
+)
+
+// similarityThreshold mirrors the default pg_trgm.similarity_threshold used by the % operator.
+const similarityThreshold = 0.3
+
+// Approximate ts_rank scores for a single matching lexeme in a field of
+// weight A (username), B (full_name) and C (bio).
+const (
+	usernameTextRank = 0.6
+	fullNameTextRank = 0.24
+	bioTextRank      = 0.12
+)
+
+// searchRank approximates the ranking of the postgres repository: exact
+// username or email match first, then username prefix, then the best of
+// trigram similarity and full-text match.
+func searchRank(user *models.User, searchQuery string) (float64, bool) {
+	q := strings.ToLower(searchQuery)
+	if q == "" {
+		return 0, true
+	}
+
+	username := strings.ToLower(user.Username)
+	fullName := ""
+	if user.FullName != nil {
+		fullName = strings.ToLower(*user.FullName)
+	}
+	bio := ""
+	if user.Bio != nil {
+		bio = strings.ToLower(*user.Bio)
+	}
+
+	email := strings.ToLower(user.Email)
+	if username == q || email == q {
+		return 3, true
+	}
+	if strings.HasPrefix(username, q) {
+		return 2, true
+	}
+
+	usernameSimilarity := trigramSimilarity(username, q)
+	fullNameSimilarity := trigramSimilarity(fullName, q)
+
+	textRank := 0.0
+	queryWords := words(q)
+	switch {
+	case containsAllWords(username, queryWords):
+		textRank = usernameTextRank
+	case containsAllWords(fullName, queryWords):
+		textRank = fullNameTextRank
+	case containsAllWords(bio, queryWords):
+		textRank = bioTextRank
+	}
+
+	matched := strings.Contains(username, q) ||
+		strings.Contains(email, q) ||
+		(fullName != "" && strings.Contains(fullName, q)) ||
+		textRank > 0 ||
+		usernameSimilarity >= similarityThreshold ||
+		fullNameSimilarity >= similarityThreshold
+	if !matched {
+		return 0, false
+	}
+
+	return max(usernameSimilarity, fullNameSimilarity, textRank), true
+}
+
+// searchMatch is a matching user together with its relevance rank.
+type searchMatch struct {
+	user *models.User
+	rank float64
+}
+
+// rankLess orders search results by rank descending, then username and id
+// ascending, for offset and cursor pages alike.
+func rankLess(a, b searchMatch) bool {
+	if a.rank != b.rank {
+		return a.rank > b.rank
+	}
+	if a.user.Username != b.user.Username {
+		return a.user.Username < b.user.Username
+	}
+	return a.user.ID < b.user.ID
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func containsAllWords(text string, queryWords []string) bool {
+	if len(queryWords) == 0 {
+		return false
+	}
+	textWords := make(map[string]struct{})
+	for _, w := range words(text) {
+		textWords[w] = struct{}{}
+	}
+	for _, w := range queryWords {
+		if _, ok := textWords[w]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// trigramSimilarity follows pg_trgm: every word is padded with two spaces in
+// front and one behind, and similarity is shared trigrams over all trigrams.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]struct{} {
+	result := make(map[string]struct{})
+	for _, w := range words(s) {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return result
+}
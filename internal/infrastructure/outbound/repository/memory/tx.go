@@ -0,0 +1,30 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type txKey struct{}
+
+// TxManager serializes transactions: each WithinTx holds one lock for its
+// whole duration, which stands in for the row locks postgres would take.
+// Writes are applied as they are made and are not rolled back on error.
+type TxManager struct {
+	mu sync.Mutex
+}
+
+func NewTxManager() *TxManager {
+	return &TxManager{}
+}
+
+// WithinTx called inside another WithinTx joins it instead of deadlocking.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(context.WithValue(ctx, txKey{}, struct{}{}))
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DSN builds the primary's connection URL from cfg, escaping credentials
+// that contain URL delimiters. scheme is "postgresql" for pgx; the migrate
+// driver expects "postgres".
+func DSN(scheme string, cfg config.Database) string {
+	query := url.Values{}
+	if cfg.SSLMode != "" {
+		query.Set("sslmode", cfg.SSLMode)
+	}
+	if cfg.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.SSLRootCert)
+	}
+
+	u := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     cfg.Host + ":" + cfg.Port,
+		Path:     "/" + cfg.DbName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// PoolConfig parses dsn and applies the pool settings in cfg. Settings left
+// at zero keep the pgxpool default, and application_name,
+// statement_timeout, sslmode and sslrootcert in the DSN take precedence over
+// cfg, so replica DSNs inherit the primary's TLS settings unless they set
+// their own.
+func PoolConfig(dsn string, cfg config.Database) (*pgxpool.Config, error) {
+	dsn, err := withTLSDefaults(dsn, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = min(cfg.MinConns, poolConfig.MaxConns)
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	params := poolConfig.ConnConfig.RuntimeParams
+	if _, ok := params["application_name"]; !ok && cfg.ApplicationName != "" {
+		params["application_name"] = cfg.ApplicationName
+	}
+	if _, ok := params["statement_timeout"]; !ok && cfg.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+// withTLSDefaults adds cfg.SSLMode and cfg.SSLRootCert to dsn unless it
+// already sets them. dsn is either a URL or a keyword/value string.
+func withTLSDefaults(dsn string, cfg config.Database) (string, error) {
+	defaults := [][2]string{{"sslmode", cfg.SSLMode}, {"sslrootcert", cfg.SSLRootCert}}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("invalid database URL: %w", err)
+		}
+		query := u.Query()
+		for _, d := range defaults {
+			if d[1] != "" && !query.Has(d[0]) {
+				query.Set(d[0], d[1])
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	for _, d := range defaults {
+		if d[1] != "" && !hasKeyword(dsn, d[0]) {
+			value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(d[1])
+			dsn = strings.TrimSpace(dsn + " " + d[0] + "='" + value + "'")
+		}
+	}
+	return dsn, nil
+}
+
+// hasKeyword reports whether the keyword/value dsn sets key.
+func hasKeyword(dsn, key string) bool {
+	return regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(key) + `\s*=`).MatchString(dsn)
+}
+
+// NewPool creates a pool for dsn tuned by cfg. Like pgxpool.New it does not
+// connect until the first query.
+func NewPool(ctx context.Context, dsn string, cfg config.Database) (*pgxpool.Pool, error) {
+	poolConfig, err := PoolConfig(dsn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
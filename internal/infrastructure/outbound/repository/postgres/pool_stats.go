@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolCounters are the cumulative pool statistics as of the last collection.
+type poolCounters struct {
+	acquires int64
+	waits    int64
+	waitTime time.Duration
+}
+
+// PoolStatsCollector periodically publishes pool.Stat() of the router's
+// primary and replica pools. Connection counts are published as they are;
+// acquire counts, wait counts and the time spent waiting on an empty pool
+// grow for the life of a pool, so only their growth since the previous
+// collection is added to the metrics. The acquired connections of all pools
+// together are reported as active connections.
+type PoolStatsCollector struct {
+	router   *Router
+	interval time.Duration
+	metrics  ports.MetricsProvider
+
+	last map[string]poolCounters
+}
+
+func NewPoolStatsCollector(router *Router, interval time.Duration, metrics ports.MetricsProvider) *PoolStatsCollector {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	return &PoolStatsCollector{
+		router:   router,
+		interval: interval,
+		metrics:  metrics,
+		last:     make(map[string]poolCounters),
+	}
+}
+
+// Start collects once, then every interval until ctx is done.
+func (c *PoolStatsCollector) Start(ctx context.Context) {
+	c.collect()
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.collect()
+			}
+		}
+	}()
+}
+
+func (c *PoolStatsCollector) collect() {
+	active := c.publish(primaryPool, c.router.primary)
+	for _, rep := range c.router.replicas {
+		active += c.publish(rep.name, rep.pool)
+	}
+	c.metrics.SetActiveConnections(active)
+}
+
+// publish reports one pool's statistics and returns its acquired connections.
+func (c *PoolStatsCollector) publish(name string, pool *pgxpool.Pool) int {
+	stat := pool.Stat()
+	c.metrics.SetDatabasePoolConnections(name,
+		int(stat.AcquiredConns()),
+		int(stat.IdleConns()),
+		int(stat.TotalConns()),
+		int(stat.MaxConns()))
+
+	current := poolCounters{
+		acquires: stat.AcquireCount(),
+		waits:    stat.EmptyAcquireCount(),
+		waitTime: stat.EmptyAcquireWaitTime(),
+	}
+	last := c.last[name]
+	c.last[name] = current
+	c.metrics.AddDatabasePoolAcquires(name,
+		max(current.acquires-last.acquires, 0),
+		max(current.waits-last.waits, 0),
+		max(current.waitTime-last.waitTime, 0))
+
+	return int(stat.AcquiredConns())
+}
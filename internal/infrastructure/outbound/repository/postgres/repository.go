@@ -5,13 +5,12 @@ import (
 	"errors"
 	"log/slog"
 	ports "pinstack-user-service/internal/domain/ports/output"
-	"pinstack-user-service/internal/utils"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"pinstack-user-service/internal/domain/models"
 
@@ -19,17 +18,18 @@ import (
 )
 
 type Repository struct {
-	pool    *pgxpool.Pool
+	router  *Router
 	log     ports.Logger
 	metrics ports.MetricsProvider
 }
 
-func NewUserRepository(pool *pgxpool.Pool, log ports.Logger, metrics ports.MetricsProvider) *Repository {
-	return &Repository{pool: pool, log: log, metrics: metrics}
+func NewUserRepository(router *Router, log ports.Logger, metrics ports.MetricsProvider) *Repository {
+	return &Repository{router: router, log: log, metrics: metrics}
 }
 
 func (r *Repository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	start := time.Now()
+	db := r.router.writer(ctx)
 	r.log.Debug("Creating user in database",
 		slog.String("username", user.Username),
 		slog.String("email", user.Email))
@@ -50,26 +50,26 @@ func (r *Repository) Create(ctx context.Context, user *models.User) (*models.Use
 	query := `
         INSERT INTO users (username, password, email, full_name, bio, avatar_url, created_at, updated_at)
         VALUES (@username, @password, @email, @full_name, @bio, @avatar_url, @created_at, @updated_at)
-        RETURNING id, username, password, email, full_name, bio, avatar_url, created_at, updated_at`
+        RETURNING id, username, email, full_name, bio, avatar_url, created_at, updated_at, version`
 
 	var createdUser models.User
-	err := r.pool.QueryRow(ctx, query, args).Scan(
+	err := db.QueryRow(ctx, query, args).Scan(
 		&createdUser.ID,
 		&createdUser.Username,
-		&createdUser.Password,
 		&createdUser.Email,
 		&createdUser.FullName,
 		&createdUser.Bio,
 		&createdUser.AvatarURL,
 		&createdUser.CreatedAt,
 		&createdUser.UpdatedAt,
+		&createdUser.Version,
 	)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("insert", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "insert", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("insert", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "insert", false)
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			if pgErr.ConstraintName == "users_username_key" {
@@ -91,7 +91,8 @@ func (r *Repository) Create(ctx context.Context, user *models.User) (*models.Use
 		return nil, err
 	}
 
-	r.metrics.IncrementDatabaseQueries("insert", true)
+	r.router.markWritten(stickyID(createdUser.ID), stickyUsername(createdUser.Username), stickyEmail(createdUser.Email))
+	r.metrics.IncrementDatabaseQueries(db.pool, "insert", true)
 	r.log.Debug("User created successfully in database",
 		slog.Int64("id", createdUser.ID),
 		slog.String("username", createdUser.Username))
@@ -100,30 +101,31 @@ func (r *Repository) Create(ctx context.Context, user *models.User) (*models.Use
 
 func (r *Repository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	start := time.Now()
+	db := r.router.reader(ctx, stickyID(id))
 	r.log.Debug("Getting user by ID from database", slog.Int64("id", id))
 
 	args := pgx.NamedArgs{"id": id}
-	query := `SELECT id, username, password, email, full_name, bio, avatar_url, created_at, updated_at
+	query := `SELECT id, username, email, full_name, bio, avatar_url, created_at, updated_at, version
                 FROM users WHERE id = @id`
-	row := r.pool.QueryRow(ctx, query, args)
+	row := db.QueryRow(ctx, query, args)
 	user := &models.User{}
 	err := row.Scan(
 		&user.ID,
 		&user.Username,
-		&user.Password,
 		&user.Email,
 		&user.FullName,
 		&user.Bio,
 		&user.AvatarURL,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("select", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("select", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Debug("User not found by id",
 				slog.Int64("id", id),
@@ -134,39 +136,104 @@ func (r *Repository) GetByID(ctx context.Context, id int64) (*models.User, error
 		return nil, err
 	}
 
-	r.metrics.IncrementDatabaseQueries("select", true)
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
 	r.log.Debug("User retrieved by ID successfully from database",
 		slog.Int64("id", user.ID),
 		slog.String("username", user.Username))
 	return user, nil
 }
 
+func (r *Repository) GetByIDs(ctx context.Context, ids []int64) ([]*models.User, error) {
+	start := time.Now()
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, stickyID(id))
+	}
+	db := r.router.reader(ctx, keys...)
+	r.log.Debug("Getting users by IDs from database", slog.Int("count", len(ids)))
+
+	args := pgx.NamedArgs{"ids": ids}
+	query := `SELECT id, username, email, full_name, bio, avatar_url, created_at, updated_at, version
+                FROM users WHERE id = ANY(@ids)`
+
+	users, err := r.queryUsers(ctx, db, query, args)
+
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
+
+	if err != nil {
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error getting users by ids", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("Users retrieved by IDs successfully from database",
+		slog.Int("requested", len(ids)),
+		slog.Int("found", len(users)))
+	return users, nil
+}
+
+func (r *Repository) queryUsers(ctx context.Context, db conn, query string, args pgx.NamedArgs) ([]*models.User, error) {
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.FullName,
+			&user.Bio,
+			&user.AvatarURL,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *Repository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	start := time.Now()
+	db := r.router.reader(ctx, stickyUsername(username))
 	r.log.Debug("Getting user by username from database", slog.String("username", username))
 
 	args := pgx.NamedArgs{"username": username}
-	query := `SELECT id, username, password, email, full_name, bio, avatar_url, created_at, updated_at
+	query := `SELECT id, username, email, full_name, bio, avatar_url, created_at, updated_at, version
                 FROM users WHERE username = @username`
-	row := r.pool.QueryRow(ctx, query, args)
+	row := db.QueryRow(ctx, query, args)
 	user := &models.User{}
 	err := row.Scan(
 		&user.ID,
 		&user.Username,
-		&user.Password,
 		&user.Email,
 		&user.FullName,
 		&user.Bio,
 		&user.AvatarURL,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("select", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("select", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Debug("User not found by username",
 				slog.String("username", username),
@@ -177,7 +244,7 @@ func (r *Repository) GetByUsername(ctx context.Context, username string) (*model
 		return nil, err
 	}
 
-	r.metrics.IncrementDatabaseQueries("select", true)
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
 	r.log.Debug("User retrieved by username successfully from database",
 		slog.Int64("id", user.ID),
 		slog.String("username", user.Username))
@@ -186,30 +253,31 @@ func (r *Repository) GetByUsername(ctx context.Context, username string) (*model
 
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	start := time.Now()
+	db := r.router.reader(ctx, stickyEmail(email))
 	r.log.Debug("Getting user by email from database", slog.String("email", email))
 
 	args := pgx.NamedArgs{"email": email}
-	query := `SELECT id, username, password, email, full_name, bio, avatar_url, created_at, updated_at
+	query := `SELECT id, username, email, full_name, bio, avatar_url, created_at, updated_at, version
                 FROM users WHERE email = @email`
-	row := r.pool.QueryRow(ctx, query, args)
+	row := db.QueryRow(ctx, query, args)
 	user := &models.User{}
 	err := row.Scan(
 		&user.ID,
 		&user.Username,
-		&user.Password,
 		&user.Email,
 		&user.FullName,
 		&user.Bio,
 		&user.AvatarURL,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("select", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("select", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Debug("User not found by email",
 				slog.String("email", email),
@@ -220,15 +288,79 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*models.User
 		return nil, err
 	}
 
-	r.metrics.IncrementDatabaseQueries("select", true)
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
 	r.log.Debug("User retrieved by email successfully from database",
 		slog.Int64("id", user.ID),
 		slog.String("email", user.Email))
 	return user, nil
 }
 
-func (r *Repository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+// GetCredentialsByID, GetCredentialsByUsername and GetCredentialsByEmail are
+// the only reads that load the password hash; every other query leaves
+// models.User.Password empty.
+func (r *Repository) GetCredentialsByID(ctx context.Context, id int64) (*models.User, error) {
+	return r.getCredentials(ctx, "id = @id", pgx.NamedArgs{"id": id}, slog.Int64("id", id))
+}
+
+func (r *Repository) GetCredentialsByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getCredentials(ctx, "username = @username", pgx.NamedArgs{"username": username}, slog.String("username", username))
+}
+
+func (r *Repository) GetCredentialsByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getCredentials(ctx, "email = @email", pgx.NamedArgs{"email": email}, slog.String("email", email))
+}
+
+// GetCredentialsByIDForUpdate locks the row until the transaction in ctx
+// ends; outside a transaction the lock is released as soon as it returns.
+func (r *Repository) GetCredentialsByIDForUpdate(ctx context.Context, id int64) (*models.User, error) {
+	return r.getCredentials(ctx, "id = @id FOR UPDATE", pgx.NamedArgs{"id": id}, slog.Int64("id", id))
+}
+
+func (r *Repository) getCredentials(ctx context.Context, condition string, args pgx.NamedArgs, lookup slog.Attr) (*models.User, error) {
+	start := time.Now()
+	// Credentials are read for logins and password changes, which must never
+	// see a replica's stale hash, and FOR UPDATE only runs on the primary.
+	db := r.router.writer(ctx)
+	r.log.Debug("Getting user credentials from database", lookup)
+
+	query := `SELECT id, username, password, email, full_name, bio, avatar_url, created_at, updated_at, version
+                FROM users WHERE ` + condition
+	row := db.QueryRow(ctx, query, args)
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Password,
+		&user.Email,
+		&user.FullName,
+		&user.Bio,
+		&user.AvatarURL,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Version,
+	)
+
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
+
+	if err != nil {
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.log.Debug("User credentials not found", lookup)
+			return nil, custom_errors.ErrUserNotFound
+		}
+		r.log.Error("Error getting user credentials", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("User credentials retrieved successfully from database", slog.Int64("id", user.ID))
+	return user, nil
+}
+
+func (r *Repository) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
 	start := time.Now()
+	db := r.router.writer(ctx)
 	r.log.Debug("Updating user in database",
 		slog.Int64("id", user.ID),
 		slog.String("username", user.Username))
@@ -240,47 +372,60 @@ func (r *Repository) Update(ctx context.Context, user *models.User) (*models.Use
 		"updated_at": updatedAt,
 	}
 
-	query := `UPDATE users SET updated_at = @updated_at`
+	query := `UPDATE users SET updated_at = @updated_at, version = version + 1`
 
-	if user.Username != "" {
+	// Optional fields are passed as pointers, so a nil one is written as NULL.
+	if fields.Has(models.UserFieldUsername) {
 		query += ", username = @username"
 		args["username"] = user.Username
 	}
-	if user.Email != "" {
+	if fields.Has(models.UserFieldEmail) {
 		query += ", email = @email"
 		args["email"] = user.Email
 	}
-	if user.FullName != nil {
+	if fields.Has(models.UserFieldFullName) {
 		query += ", full_name = @full_name"
-		args["full_name"] = utils.StrPtrToStr(user.FullName)
+		args["full_name"] = user.FullName
 	}
-	if user.Bio != nil {
+	if fields.Has(models.UserFieldBio) {
 		query += ", bio = @bio"
-		args["bio"] = utils.StrPtrToStr(user.Bio)
+		args["bio"] = user.Bio
 	}
 
-	query += ` WHERE id = @id 
-        RETURNING id, username, password, email, full_name, bio, avatar_url, created_at, updated_at`
+	query += ` WHERE id = @id`
+	if user.Version > 0 {
+		query += " AND version = @version"
+		args["version"] = user.Version
+	}
+	query += ` RETURNING id, username, email, full_name, bio, avatar_url, created_at, updated_at, version`
 
 	var updatedUser models.User
-	err := r.pool.QueryRow(ctx, query, args).Scan(
+	err := db.QueryRow(ctx, query, args).Scan(
 		&updatedUser.ID,
 		&updatedUser.Username,
-		&updatedUser.Password,
 		&updatedUser.Email,
 		&updatedUser.FullName,
 		&updatedUser.Bio,
 		&updatedUser.AvatarURL,
 		&updatedUser.CreatedAt,
 		&updatedUser.UpdatedAt,
+		&updatedUser.Version,
 	)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("update", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "update", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("update", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "update", false)
 		if errors.Is(err, pgx.ErrNoRows) {
+			if user.Version > 0 {
+				if exists, existsErr := r.exists(ctx, user.ID); existsErr == nil && exists {
+					r.log.Debug("User version conflict during update",
+						slog.Int64("id", user.ID),
+						slog.Int64("version", user.Version))
+					return nil, models.ErrVersionConflict
+				}
+			}
 			r.log.Debug("User not found for update",
 				slog.Int64("id", user.ID),
 				slog.String("error", err.Error()))
@@ -307,104 +452,381 @@ func (r *Repository) Update(ctx context.Context, user *models.User) (*models.Use
 		return nil, err
 	}
 
-	r.metrics.IncrementDatabaseQueries("update", true)
+	r.router.markWritten(stickyID(updatedUser.ID), stickyUsername(updatedUser.Username), stickyEmail(updatedUser.Email))
+	r.metrics.IncrementDatabaseQueries(db.pool, "update", true)
 	r.log.Debug("User updated successfully in database",
 		slog.Int64("id", updatedUser.ID),
 		slog.String("username", updatedUser.Username))
 	return &updatedUser, nil
 }
 
+// exists tells a version conflict apart from a missing user after a
+// versioned update matched no rows.
+func (r *Repository) exists(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.router.writer(ctx).QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = @id)`,
+		pgx.NamedArgs{"id": id}).Scan(&exists)
+	return exists, err
+}
+
 func (r *Repository) Delete(ctx context.Context, id int64) error {
 	start := time.Now()
+	db := r.router.writer(ctx)
 	r.log.Debug("Deleting user from database", slog.Int64("id", id))
 
 	args := pgx.NamedArgs{"id": id}
-	query := `DELETE FROM users WHERE id = @id`
-	result, err := r.pool.Exec(ctx, query, args)
+	query := `DELETE FROM users WHERE id = @id RETURNING username, email`
+	var username, email string
+	err := db.QueryRow(ctx, query, args).Scan(&username, &email)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("delete", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "delete", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("delete", false)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.metrics.IncrementDatabaseQueries(db.pool, "delete", true)
+			return custom_errors.ErrUserNotFound
+		}
+		r.metrics.IncrementDatabaseQueries(db.pool, "delete", false)
 		r.log.Error("Error deleting user", slog.String("error", err.Error()))
 		return err
 	}
-	if result.RowsAffected() == 0 {
-		r.metrics.IncrementDatabaseQueries("delete", true)
-		return custom_errors.ErrUserNotFound
-	}
 
-	r.metrics.IncrementDatabaseQueries("delete", true)
+	r.router.markWritten(stickyID(id), stickyUsername(username), stickyEmail(email))
+	r.metrics.IncrementDatabaseQueries(db.pool, "delete", true)
 	r.log.Debug("User deleted successfully from database", slog.Int64("id", id))
 	return nil
 }
 
+// searchMatchedCTE selects users matching @query together with a relevance
+// rank: an exact username or email match scores 3, a username prefix 2, and
+// anything else the best of trigram similarity and full-text rank (0..1).
+// A substring of the email matches too, ranked like any other weak match.
+const searchMatchedCTE = `
+            WITH params AS (
+                SELECT lower(@query::text) AS q,
+                       plainto_tsquery('simple', @query::text) AS tsq
+            ),
+            matched AS (
+                SELECT u.id, u.username, u.email, u.full_name, u.bio, u.avatar_url, u.created_at, u.updated_at, u.version,
+                       (CASE
+                           WHEN p.q = '' THEN 0
+                           WHEN lower(u.username) = p.q OR lower(u.email) = p.q THEN 3
+                           WHEN lower(u.username) LIKE @prefix_pattern THEN 2
+                           ELSE greatest(
+                               similarity(lower(u.username), p.q),
+                               similarity(lower(coalesce(u.full_name, '')), p.q),
+                               least(ts_rank(u.search_vector, p.tsq), 1)
+                           )
+                       END)::float8 AS rank
+                FROM users u, params p
+                WHERE p.q = ''
+                   OR lower(u.email) LIKE @contains_pattern
+                   OR lower(u.username) LIKE @contains_pattern
+                   OR lower(u.full_name) LIKE @contains_pattern
+                   OR u.search_vector @@ p.tsq
+                   OR lower(u.username) % p.q
+                   OR lower(u.full_name) % p.q
+            )`
+
+func searchArgs(searchQuery string) pgx.NamedArgs {
+	escaped := escapeLikePattern(strings.ToLower(searchQuery))
+	return pgx.NamedArgs{
+		"query":            searchQuery,
+		"prefix_pattern":   escaped + "%",
+		"contains_pattern": "%" + escaped + "%",
+	}
+}
+
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func (r *Repository) Search(ctx context.Context, searchQuery string, offset, limit int) ([]*models.User, int, error) {
 	start := time.Now()
+	db := r.router.reader(ctx)
 	r.log.Debug("Searching users in database",
 		slog.String("query", searchQuery),
 		slog.Int("offset", offset),
 		slog.Int("limit", limit))
 
-	args := pgx.NamedArgs{
-		"query":  searchQuery,
-		"offset": offset,
-		"limit":  limit,
-	}
+	args := searchArgs(searchQuery)
+	args["offset"] = offset
+	args["limit"] = limit
 
-	query := `
-            SELECT id, username, password, email, full_name, bio, avatar_url, created_at, updated_at FROM users 
-            WHERE 
-                username ILIKE '%' || @query || '%' OR
-                email ILIKE '%' || @query || '%' OR
-                full_name ILIKE '%' || @query || '%'
+	query := searchMatchedCTE + `
+            SELECT id, username, email, full_name, bio, avatar_url, created_at, updated_at, version, rank,
+                   COUNT(*) OVER() AS total
+            FROM matched
+            ORDER BY rank DESC, username, id
             LIMIT @limit OFFSET @offset
             `
 
-	rows, err := r.pool.Query(ctx, query, args)
+	hits, total, err := r.querySearchPage(ctx, db, query, args)
+	if err == nil && len(hits) == 0 && offset > 0 {
+		// The window count is only available when the page has rows, so an
+		// offset past the end needs a separate count to report the real total.
+		total, err = r.countSearchMatches(ctx, db, args)
+	}
+
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
+
 	if err != nil {
-		duration := time.Since(start)
-		r.metrics.RecordDatabaseQueryDuration("select", duration)
-		r.metrics.IncrementDatabaseQueries("select", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
 		r.log.Error("Error searching users", slog.String("error", err.Error()))
 		return nil, 0, err
 	}
+
+	users := make([]*models.User, 0, len(hits))
+	for _, hit := range hits {
+		users = append(users, hit.User)
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("Search completed successfully in database",
+		slog.String("query", searchQuery),
+		slog.Int("count", len(users)),
+		slog.Int("total", total))
+	return users, total, nil
+}
+
+func (r *Repository) SearchAfter(ctx context.Context, searchQuery string, cursor *models.SearchCursor, limit int) ([]*models.SearchHit, int, error) {
+	start := time.Now()
+	db := r.router.reader(ctx)
+	r.log.Debug("Searching users in database by cursor",
+		slog.String("query", searchQuery),
+		slog.Bool("has_cursor", cursor != nil),
+		slog.Int("limit", limit))
+
+	args := searchArgs(searchQuery)
+	args["limit"] = limit
+
+	// Pages are ordered like Search, by rank, then username and id, and the
+	// keyset seeks strictly after the last row of the previous page. Only the
+	// first page counts the matches; later pages carry that total in the
+	// cursor.
+	keyset := "TRUE"
+	total := "COUNT(*) OVER()"
+	if cursor != nil {
+		keyset = `(m.rank < @after_rank::float8
+                OR (m.rank = @after_rank::float8 AND (m.username, m.id) > (@after_username::text, @after_id::bigint)))`
+		total = "0"
+		args["after_rank"] = cursor.Rank
+		args["after_username"] = cursor.Username
+		args["after_id"] = cursor.ID
+	}
+
+	query := searchMatchedCTE + `
+            SELECT m.id, m.username, m.email, m.full_name, m.bio, m.avatar_url, m.created_at, m.updated_at, m.version, m.rank,
+                   ` + total + ` AS total
+            FROM matched m
+            WHERE ` + keyset + `
+            ORDER BY m.rank DESC, m.username, m.id
+            LIMIT @limit
+            `
+
+	hits, count, err := r.querySearchPage(ctx, db, query, args)
+
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
+
+	if err != nil {
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error searching users by cursor", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	if cursor != nil {
+		count = cursor.Total
+	}
+	for _, hit := range hits {
+		hit.Cursor.Total = count
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("Cursor search completed successfully in database",
+		slog.String("query", searchQuery),
+		slog.Int("count", len(hits)),
+		slog.Int("total", count))
+	return hits, count, nil
+}
+
+func (r *Repository) querySearchPage(ctx context.Context, db conn, query string, args pgx.NamedArgs) ([]*models.SearchHit, int, error) {
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		return nil, 0, err
+	}
 	defer rows.Close()
 
-	var users []*models.User
+	hits := make([]*models.SearchHit, 0)
+	total := 0
 	for rows.Next() {
-		var user models.User
+		var (
+			user models.User
+			rank float64
+		)
 		err := rows.Scan(
 			&user.ID,
 			&user.Username,
-			&user.Password,
 			&user.Email,
 			&user.FullName,
 			&user.Bio,
 			&user.AvatarURL,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
+			&rank,
+			&total,
 		)
 		if err != nil {
-			r.log.Error("Error getting user", slog.String("error", err.Error()))
 			return nil, 0, err
 		}
-		users = append(users, &user)
+		hits = append(hits, &models.SearchHit{
+			User:   &user,
+			Cursor: models.SearchCursor{Rank: rank, Username: user.Username, ID: user.ID},
+		})
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return hits, total, nil
+}
+
+func (r *Repository) countSearchMatches(ctx context.Context, db conn, args pgx.NamedArgs) (int, error) {
+	query := searchMatchedCTE + ` SELECT COUNT(*) FROM matched`
+
+	var total int
+	if err := db.QueryRow(ctx, query, args).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *Repository) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	start := time.Now()
+	db := r.router.reader(ctx)
+	r.log.Debug("Autocompleting usernames in database",
+		slog.String("prefix", prefix),
+		slog.Int("limit", limit))
+
+	args := pgx.NamedArgs{
+		"pattern": escapeLikePattern(strings.ToLower(prefix)) + "%",
+		"limit":   limit,
+	}
+
+	// ORDER BY ... USING ~<~ matches the text_pattern_ops index, so the
+	// prefix range scan stops after limit rows instead of sorting the matches.
+	query := `
+            SELECT username
+            FROM users
+            WHERE lower(username) LIKE @pattern
+            ORDER BY lower(username) USING ~<~
+            LIMIT @limit`
+
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		r.metrics.RecordDatabaseQueryDuration(db.pool, "select", time.Since(start))
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error autocompleting usernames", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	usernames := make([]string, 0, limit)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			r.metrics.RecordDatabaseQueryDuration(db.pool, "select", time.Since(start))
+			r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+			r.log.Error("Error scanning username", slog.String("error", err.Error()))
+			return nil, err
+		}
+		usernames = append(usernames, username)
+	}
+	err = rows.Err()
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("select", duration)
-	r.metrics.IncrementDatabaseQueries("select", true)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
 
-	r.log.Debug("Search completed successfully in database",
-		slog.String("query", searchQuery),
-		slog.Int("count", len(users)))
-	return users, len(users), nil
+	if err != nil {
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error autocompleting usernames", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("Usernames autocompleted successfully in database",
+		slog.String("prefix", prefix),
+		slog.Int("count", len(usernames)))
+	return usernames, nil
+}
+
+// ListUsernames returns up to limit usernames of users with an id above
+// afterID, in id order, together with the id of the last one so the caller
+// can continue from there.
+func (r *Repository) ListUsernames(ctx context.Context, afterID int64, limit int) ([]string, int64, error) {
+	start := time.Now()
+	db := r.router.reader(ctx)
+	r.log.Debug("Listing usernames in database",
+		slog.Int64("after_id", afterID),
+		slog.Int("limit", limit))
+
+	args := pgx.NamedArgs{
+		"after_id": afterID,
+		"limit":    limit,
+	}
+
+	query := `
+            SELECT id, username
+            FROM users
+            WHERE id > @after_id
+            ORDER BY id
+            LIMIT @limit`
+
+	rows, err := db.Query(ctx, query, args)
+	if err != nil {
+		r.metrics.RecordDatabaseQueryDuration(db.pool, "select", time.Since(start))
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error listing usernames", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	usernames := make([]string, 0, limit)
+	lastID := afterID
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&lastID, &username); err != nil {
+			r.metrics.RecordDatabaseQueryDuration(db.pool, "select", time.Since(start))
+			r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+			r.log.Error("Error scanning username", slog.String("error", err.Error()))
+			return nil, 0, err
+		}
+		usernames = append(usernames, username)
+	}
+	err = rows.Err()
+
+	duration := time.Since(start)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "select", duration)
+
+	if err != nil {
+		r.metrics.IncrementDatabaseQueries(db.pool, "select", false)
+		r.log.Error("Error listing usernames", slog.String("error", err.Error()))
+		return nil, 0, err
+	}
+
+	r.metrics.IncrementDatabaseQueries(db.pool, "select", true)
+	r.log.Debug("Usernames listed successfully in database",
+		slog.Int64("after_id", afterID),
+		slog.Int("count", len(usernames)))
+	return usernames, lastID, nil
 }
 
 func (r *Repository) UpdatePassword(ctx context.Context, id int64, newPassword string) error {
 	start := time.Now()
+	db := r.router.writer(ctx)
 	r.log.Debug("Updating user password in database", slog.Int64("id", id))
 
 	updatedAt := pgtype.Timestamptz{Time: time.Now(), Valid: true}
@@ -417,18 +839,19 @@ func (r *Repository) UpdatePassword(ctx context.Context, id int64, newPassword s
 	query := `
         UPDATE users 
         SET password = @password,
-            updated_at = @updated_at
+            updated_at = @updated_at,
+            version = version + 1
         WHERE id = @id
         RETURNING id`
 
 	var userID int64
-	err := r.pool.QueryRow(ctx, query, args).Scan(&userID)
+	err := db.QueryRow(ctx, query, args).Scan(&userID)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("update", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "update", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("update", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "update", false)
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Debug("User not found for password update",
 				slog.Int64("id", id),
@@ -439,13 +862,15 @@ func (r *Repository) UpdatePassword(ctx context.Context, id int64, newPassword s
 		return err
 	}
 
-	r.metrics.IncrementDatabaseQueries("update", true)
+	r.router.markWritten(stickyID(id))
+	r.metrics.IncrementDatabaseQueries(db.pool, "update", true)
 	r.log.Debug("User password updated successfully in database", slog.Int64("id", id))
 	return nil
 }
 
 func (r *Repository) UpdateAvatar(ctx context.Context, id int64, avatarURL string) error {
 	start := time.Now()
+	db := r.router.writer(ctx)
 	r.log.Debug("Updating user avatar in database",
 		slog.Int64("id", id),
 		slog.String("avatarURL", avatarURL))
@@ -461,18 +886,19 @@ func (r *Repository) UpdateAvatar(ctx context.Context, id int64, avatarURL strin
 	query := `
         UPDATE users 
         SET avatar_url = @avatar_url,
-            updated_at = @updated_at
+            updated_at = @updated_at,
+            version = version + 1
         WHERE id = @id
         RETURNING id`
 
 	var userID int64
-	err := r.pool.QueryRow(ctx, query, args).Scan(&userID)
+	err := db.QueryRow(ctx, query, args).Scan(&userID)
 
 	duration := time.Since(start)
-	r.metrics.RecordDatabaseQueryDuration("update", duration)
+	r.metrics.RecordDatabaseQueryDuration(db.pool, "update", duration)
 
 	if err != nil {
-		r.metrics.IncrementDatabaseQueries("update", false)
+		r.metrics.IncrementDatabaseQueries(db.pool, "update", false)
 		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Debug("User not found for avatar update",
 				slog.Int64("id", id),
@@ -483,7 +909,8 @@ func (r *Repository) UpdateAvatar(ctx context.Context, id int64, avatarURL strin
 		return err
 	}
 
-	r.metrics.IncrementDatabaseQueries("update", true)
+	r.router.markWritten(stickyID(id))
+	r.metrics.IncrementDatabaseQueries(db.pool, "update", true)
 	r.log.Debug("User avatar updated successfully in database", slog.Int64("id", id))
 	return nil
 }
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/infrastructure/config"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const primaryPool = "primary"
+
+// replicaStatusQuery reports whether a server is a standby, whether its WAL
+// receiver is streaming from the primary and how far its replayed data trails
+// the primary. Replay lag alone cannot tell a caught-up replica from one whose
+// replication broke (receive and replay positions stop together) or from a
+// server that is not a standby at all, hence the other two columns. Reading
+// pg_stat_wal_receiver's status needs pg_read_all_stats (e.g. via pg_monitor);
+// without it the replica never counts as streaming.
+const replicaStatusQuery = `
+        SELECT pg_is_in_recovery(),
+               EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming'),
+               CASE
+                   WHEN pg_last_wal_receive_lsn() IS NOT DISTINCT FROM pg_last_wal_replay_lsn() THEN 0
+                   ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+               END::float8`
+
+// replicaStatus is what one check found out about a replica.
+type replicaStatus struct {
+	inRecovery bool
+	streaming  bool
+	lag        time.Duration
+}
+
+// problem says why a replica with this status must not serve reads, or
+// returns "" if it may.
+func (s replicaStatus) problem(maxLag time.Duration) string {
+	switch {
+	case !s.inRecovery:
+		return "not a standby"
+	case !s.streaming:
+		return "not streaming from the primary"
+	case maxLag > 0 && s.lag > maxLag:
+		return "lagging"
+	default:
+		return ""
+	}
+}
+
+// conn is the pool or transaction a query runs on, named for metrics.
+type conn struct {
+	querier
+	pool string
+}
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	checked atomic.Bool
+}
+
+// Router sends writes and transactions to the primary and plain reads to a
+// healthy replica, round robin. Replicas are healthy while their check
+// succeeds and finds a standby streaming from the primary no more than maxLag
+// behind; with none healthy, reads go to the primary.
+//
+// Replication is asynchronous, so after a write the keys it touched (user id,
+// username, email) are pinned to the primary for stickyWindow, letting the
+// writer read its own write. Pins are kept per process; searches are never
+// pinned, but callers that cache search results cluster-wide ask for primary
+// reads (ports.WithPrimaryReads).
+type Router struct {
+	primary       *pgxpool.Pool
+	replicas      []*replica
+	maxLag        time.Duration
+	checkInterval time.Duration
+	stickyWindow  time.Duration
+	log           ports.Logger
+	metrics       ports.MetricsProvider
+
+	next atomic.Uint64
+
+	mu     sync.Mutex
+	sticky map[string]time.Time
+}
+
+func NewRouter(primary *pgxpool.Pool, replicas []*pgxpool.Pool, cfg config.Database, log ports.Logger, metrics ports.MetricsProvider) *Router {
+	r := &Router{
+		primary:       primary,
+		maxLag:        cfg.ReplicaMaxLag,
+		checkInterval: cfg.ReplicaCheckInterval,
+		stickyWindow:  cfg.ReadYourWritesWindow,
+		log:           log,
+		metrics:       metrics,
+		sticky:        make(map[string]time.Time),
+	}
+	if r.checkInterval <= 0 {
+		r.checkInterval = 5 * time.Second
+	}
+	for i, pool := range replicas {
+		r.replicas = append(r.replicas, &replica{name: "replica-" + strconv.Itoa(i), pool: pool})
+	}
+	return r
+}
+
+// Start checks the replicas once, so reads only move to replicas known to be
+// caught up, then again every check interval until ctx is done.
+func (r *Router) Start(ctx context.Context) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	r.checkReplicas(ctx)
+	go func() {
+		ticker := time.NewTicker(r.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.checkReplicas(ctx)
+				r.pruneSticky()
+			}
+		}
+	}()
+}
+
+func (r *Router) checkReplicas(ctx context.Context) {
+	for _, rep := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.checkInterval)
+		var (
+			status     replicaStatus
+			lagSeconds float64
+		)
+		err := rep.pool.QueryRow(checkCtx, replicaStatusQuery).Scan(&status.inRecovery, &status.streaming, &lagSeconds)
+		cancel()
+
+		status.lag = time.Duration(lagSeconds * float64(time.Second))
+		problem := ""
+		if err == nil {
+			problem = status.problem(r.maxLag)
+			r.metrics.SetDatabaseReplicaLag(rep.name, status.lag)
+		}
+		healthy := err == nil && problem == ""
+		// Log state changes, and the first check so a replica that is down
+		// from the start is reported too.
+		if rep.healthy.Swap(healthy) == healthy && rep.checked.Swap(true) {
+			continue
+		}
+		switch {
+		case healthy:
+			r.log.Info("Postgres replica is serving reads",
+				slog.String("replica", rep.name),
+				slog.Duration("lag", status.lag))
+		case err != nil:
+			r.log.Warn("Postgres replica check failed, routing its reads to other pools",
+				slog.String("replica", rep.name),
+				slog.String("error", err.Error()))
+		default:
+			r.log.Warn("Postgres replica is unhealthy, routing its reads to other pools",
+				slog.String("replica", rep.name),
+				slog.String("reason", problem),
+				slog.Duration("lag", status.lag),
+				slog.Duration("max_lag", r.maxLag))
+		}
+	}
+}
+
+// writer returns the transaction in ctx, or the primary.
+func (r *Router) writer(ctx context.Context) conn {
+	if tx, ok := txFromContext(ctx); ok {
+		return conn{querier: tx, pool: primaryPool}
+	}
+	return conn{querier: r.primary, pool: primaryPool}
+}
+
+// reader returns a connection for a read of the given sticky keys: the
+// transaction in ctx, the primary if ctx asks for primary reads or any key was
+// written recently, or else the next healthy replica.
+func (r *Router) reader(ctx context.Context, keys ...string) conn {
+	if _, ok := txFromContext(ctx); ok || len(r.replicas) == 0 || ports.PrimaryReads(ctx) || r.isSticky(keys...) {
+		return r.writer(ctx)
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return conn{querier: rep.pool, pool: rep.name}
+		}
+	}
+	return r.writer(ctx)
+}
+
+// markWritten pins keys to the primary for the sticky window.
+func (r *Router) markWritten(keys ...string) {
+	if len(r.replicas) == 0 || r.stickyWindow <= 0 {
+		return
+	}
+
+	until := time.Now().Add(r.stickyWindow)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		r.sticky[key] = until
+	}
+}
+
+func (r *Router) isSticky(keys ...string) bool {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		if until, ok := r.sticky[key]; ok && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) pruneSticky() {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, until := range r.sticky {
+		if !now.Before(until) {
+			delete(r.sticky, key)
+		}
+	}
+}
+
+func stickyID(id int64) string { return "id:" + strconv.FormatInt(id, 10) }
+
+func stickyUsername(username string) string { return "username:" + username }
+
+func stickyEmail(email string) string { return "email:" + email }
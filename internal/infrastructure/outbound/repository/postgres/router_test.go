@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ports "pinstack-user-service/internal/domain/ports/output"
+	"pinstack-user-service/internal/infrastructure/config"
+	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPool returns a pool that never connects: pgxpool dials lazily and
+// these tests only look at which pool a query would be routed to.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), "postgresql://postgres@127.0.0.1:1/test")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestRouter_Reader(t *testing.T) {
+	primary := newTestPool(t)
+	router := NewRouter(primary, []*pgxpool.Pool{newTestPool(t), newTestPool(t)},
+		config.Database{ReadYourWritesWindow: time.Minute}, logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+	ctx := context.Background()
+
+	// Replicas serve nothing until a check finds them healthy.
+	assert.Equal(t, primaryPool, router.reader(ctx).pool)
+
+	router.replicas[0].healthy.Store(true)
+	router.replicas[1].healthy.Store(true)
+	seen := map[string]bool{}
+	for range 4 {
+		seen[router.reader(ctx, stickyID(1)).pool] = true
+	}
+	assert.Equal(t, map[string]bool{"replica-0": true, "replica-1": true}, seen)
+
+	router.replicas[0].healthy.Store(false)
+	for range 4 {
+		assert.Equal(t, "replica-1", router.reader(ctx).pool)
+	}
+
+	t.Run("read your writes", func(t *testing.T) {
+		router.markWritten(stickyID(1), stickyUsername("alice"))
+
+		assert.Equal(t, primaryPool, router.reader(ctx, stickyID(1)).pool)
+		assert.Equal(t, primaryPool, router.reader(ctx, stickyID(2), stickyUsername("alice")).pool)
+		assert.Equal(t, "replica-1", router.reader(ctx, stickyID(2)).pool)
+
+		router.sticky[stickyID(1)] = time.Now().Add(-time.Second)
+		router.pruneSticky()
+		assert.NotContains(t, router.sticky, stickyID(1))
+		assert.Equal(t, "replica-1", router.reader(ctx, stickyID(1)).pool)
+	})
+
+	t.Run("primary reads", func(t *testing.T) {
+		ctx := ports.WithPrimaryReads(ctx)
+		assert.Equal(t, primaryPool, router.reader(ctx).pool)
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		ctx := context.WithValue(ctx, txKey{}, nilTx{})
+		assert.Equal(t, primaryPool, router.reader(ctx).pool)
+		assert.IsType(t, nilTx{}, router.reader(ctx).querier)
+	})
+}
+
+func TestRouter_NoReplicas(t *testing.T) {
+	primary := newTestPool(t)
+	router := NewRouter(primary, nil, config.Database{ReadYourWritesWindow: time.Minute},
+		logger.New("test"), prometheus.NewPrometheusMetricsProvider())
+
+	router.markWritten(stickyID(1))
+	assert.Empty(t, router.sticky)
+	assert.Same(t, primary, router.reader(context.Background(), stickyID(1)).querier)
+}
+
+// nilTx stands in for an open transaction; routing never calls it.
+type nilTx struct{ pgx.Tx }
+
+func TestReplicaStatus_Problem(t *testing.T) {
+	tests := []struct {
+		name   string
+		status replicaStatus
+		maxLag time.Duration
+		want   string
+	}{
+		{"streaming and caught up", replicaStatus{inRecovery: true, streaming: true}, time.Second, ""},
+		{"within max lag", replicaStatus{inRecovery: true, streaming: true, lag: time.Second}, time.Second, ""},
+		{"any lag accepted", replicaStatus{inRecovery: true, streaming: true, lag: time.Hour}, 0, ""},
+		{"lagging", replicaStatus{inRecovery: true, streaming: true, lag: 2 * time.Second}, time.Second, "lagging"},
+		// A broken WAL receiver leaves receive and replay positions equal,
+		// so the lag reads as zero.
+		{"replication broken", replicaStatus{inRecovery: true}, time.Second, "not streaming from the primary"},
+		{"not a standby", replicaStatus{}, time.Second, "not a standby"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.problem(tt.maxLag))
+		})
+	}
+}
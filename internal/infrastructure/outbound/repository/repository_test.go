@@ -3,10 +3,16 @@ package user_repository_test
 import (
 	"context"
 	"github.com/soloda1/pinstack-proto-definitions/custom_errors"
+	"os"
+	"pinstack-user-service/internal/infrastructure/config"
 	"pinstack-user-service/internal/infrastructure/logger"
+	"pinstack-user-service/internal/infrastructure/outbound/metrics/prometheus"
 	"pinstack-user-service/internal/infrastructure/outbound/repository/memory"
+	"pinstack-user-service/internal/infrastructure/outbound/repository/postgres"
+	"strings"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -20,6 +26,37 @@ func setupTest(t *testing.T) (user_repository.UserRepository, func()) {
 	return repo, func() {}
 }
 
+// forEachRepository runs test against every repository implementation so
+// they are held to the same behavior. Postgres is included when
+// TEST_DATABASE_URL points at a migrated database; its users are truncated
+// before each run, so the database name must end in "_test".
+func forEachRepository(t *testing.T, test func(t *testing.T, repo user_repository.UserRepository)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, memory.NewUserRepository(logger.New("test")))
+	})
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		return
+	}
+	t.Run("postgres", func(t *testing.T) {
+		pool, err := pgxpool.New(context.Background(), dsn)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		var name string
+		require.NoError(t, pool.QueryRow(context.Background(), "SELECT current_database()").Scan(&name))
+		if !strings.HasSuffix(name, "_test") {
+			t.Fatalf("refusing to truncate users in %q: TEST_DATABASE_URL must name a database ending in _test", name)
+		}
+		_, err = pool.Exec(context.Background(), "TRUNCATE users RESTART IDENTITY CASCADE")
+		require.NoError(t, err)
+
+		log, metrics := logger.New("test"), prometheus.NewPrometheusMetricsProvider()
+		test(t, postgres.NewUserRepository(postgres.NewRouter(pool, nil, config.Database{}, log, metrics), log, metrics))
+	})
+}
+
 func TestUserRepository_Create(t *testing.T) {
 	repo, cleanup := setupTest(t)
 	defer cleanup()
@@ -41,7 +78,6 @@ func TestUserRepository_Create(t *testing.T) {
 				ID:       1,
 				Username: "testuser",
 				Email:    "test@example.com",
-				Password: "password123",
 			},
 			wantErr: nil,
 		},
@@ -140,6 +176,27 @@ func TestUserRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestUserRepository_GetByIDs(t *testing.T) {
+	repo, cleanup := setupTest(t)
+	defer cleanup()
+
+	first, err := repo.Create(context.Background(), &models.User{Username: "first", Email: "first@example.com"})
+	require.NoError(t, err)
+	second, err := repo.Create(context.Background(), &models.User{Username: "second", Email: "second@example.com"})
+	require.NoError(t, err)
+
+	got, err := repo.GetByIDs(context.Background(), []int64{second.ID, 999, first.ID})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	gotIDs := []int64{got[0].ID, got[1].ID}
+	assert.ElementsMatch(t, []int64{first.ID, second.ID}, gotIDs)
+
+	got, err = repo.GetByIDs(context.Background(), []int64{999})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
 func TestUserRepository_GetByUsername(t *testing.T) {
 	repo, cleanup := setupTest(t)
 	defer cleanup()
@@ -246,6 +303,38 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	}
 }
 
+func TestUserRepository_GetCredentials(t *testing.T) {
+	repo, cleanup := setupTest(t)
+	defer cleanup()
+
+	created, err := repo.Create(context.Background(), &models.User{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "hash",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, created.Password)
+
+	got, err := repo.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Password)
+
+	credentials, err := repo.GetCredentialsByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hash", credentials.Password)
+
+	credentials, err = repo.GetCredentialsByUsername(context.Background(), "testuser")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", credentials.Password)
+
+	credentials, err = repo.GetCredentialsByEmail(context.Background(), "test@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", credentials.Password)
+
+	_, err = repo.GetCredentialsByEmail(context.Background(), "missing@example.com")
+	assert.Equal(t, custom_errors.ErrUserNotFound, err)
+}
+
 func TestUserRepository_Update(t *testing.T) {
 	repo, cleanup := setupTest(t)
 	defer cleanup()
@@ -286,7 +375,6 @@ func TestUserRepository_Update(t *testing.T) {
 				ID:       created1.ID,
 				Username: "updateduser",
 				Email:    "updated@example.com",
-				Password: "newpassword",
 			},
 			wantErr: nil,
 		},
@@ -327,7 +415,7 @@ func TestUserRepository_Update(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := repo.Update(context.Background(), tt.user)
+			got, err := repo.Update(context.Background(), tt.user, models.UpdatableUserFields)
 
 			if tt.wantErr != nil {
 				assert.Error(t, err)
@@ -340,11 +428,144 @@ func TestUserRepository_Update(t *testing.T) {
 				assert.Equal(t, tt.want.Username, got.Username)
 				assert.Equal(t, tt.want.Email, got.Email)
 				assert.Equal(t, tt.want.Password, got.Password)
+
+				credentials, err := repo.GetCredentialsByID(context.Background(), tt.want.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, "password123", credentials.Password)
 			}
 		})
 	}
 }
 
+func TestUserRepository_UpdateConflicts(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo user_repository.UserRepository) {
+		ctx := context.Background()
+
+		created, err := repo.Create(ctx, &models.User{
+			Username: "testuser",
+			Email:    "test@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+		other, err := repo.Create(ctx, &models.User{
+			Username: "anotheruser",
+			Email:    "another@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+
+		_, err = repo.Update(ctx, &models.User{ID: 999, Username: "nonexistent"},
+			models.UserFields{models.UserFieldUsername})
+		assert.Equal(t, custom_errors.ErrUserNotFound, err)
+
+		_, err = repo.Update(ctx, &models.User{ID: created.ID, Username: other.Username},
+			models.UserFields{models.UserFieldUsername})
+		assert.Equal(t, custom_errors.ErrUsernameExists, err)
+
+		_, err = repo.Update(ctx, &models.User{ID: created.ID, Email: other.Email},
+			models.UserFields{models.UserFieldEmail})
+		assert.Equal(t, custom_errors.ErrEmailExists, err)
+
+		got, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.Username, got.Username)
+		assert.Equal(t, created.Email, got.Email)
+	})
+}
+
+func TestUserRepository_UpdateVersion(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo user_repository.UserRepository) {
+		ctx := context.Background()
+
+		created, err := repo.Create(ctx, &models.User{
+			Username: "testuser",
+			Email:    "test@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), created.Version)
+
+		updated, err := repo.Update(ctx, &models.User{
+			ID:       created.ID,
+			Username: "first",
+			Email:    created.Email,
+			Version:  created.Version,
+		}, models.UserFields{models.UserFieldUsername})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+
+		// A second writer still holding the original version loses.
+		_, err = repo.Update(ctx, &models.User{
+			ID:       created.ID,
+			Username: "second",
+			Email:    created.Email,
+			Version:  created.Version,
+		}, models.UserFields{models.UserFieldUsername})
+		assert.Equal(t, models.ErrVersionConflict, err)
+
+		// Without a version the update is unconditional.
+		updated, err = repo.Update(ctx, &models.User{
+			ID:       created.ID,
+			Username: "third",
+			Email:    created.Email,
+		}, models.UserFields{models.UserFieldUsername})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), updated.Version)
+
+		require.NoError(t, repo.UpdateAvatar(ctx, created.ID, "avatar.png"))
+		got, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), got.Version)
+	})
+}
+
+func TestUserRepository_UpdateFields(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo user_repository.UserRepository) {
+		ctx := context.Background()
+
+		created, err := repo.Create(ctx, &models.User{
+			Username: "testuser",
+			Email:    "test@example.com",
+			Password: "password123",
+			FullName: stringPtr("Test User"),
+			Bio:      stringPtr("Hello"),
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo.UpdateAvatar(ctx, created.ID, "avatar.png"))
+
+		// Bio is named but nil, so it is cleared; the email is set on the
+		// user but not named, so it keeps its stored value.
+		updated, err := repo.Update(ctx, &models.User{
+			ID:       created.ID,
+			Email:    "ignored@example.com",
+			FullName: stringPtr("Renamed"),
+		}, models.UserFields{models.UserFieldFullName, models.UserFieldBio})
+		require.NoError(t, err)
+		assert.Equal(t, "testuser", updated.Username)
+		assert.Equal(t, "test@example.com", updated.Email)
+		assert.Equal(t, stringPtr("Renamed"), updated.FullName)
+		assert.Nil(t, updated.Bio)
+		assert.Equal(t, stringPtr("avatar.png"), updated.AvatarURL)
+
+		got, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, updated.FullName, got.FullName)
+		assert.Nil(t, got.Bio)
+		assert.Equal(t, stringPtr("avatar.png"), got.AvatarURL)
+
+		credentials, err := repo.GetCredentialsByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "password123", credentials.Password)
+
+		// With no fields named, nothing but the version changes.
+		touched, err := repo.Update(ctx, &models.User{ID: created.ID}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, got.Username, touched.Username)
+		assert.Equal(t, got.FullName, touched.FullName)
+		assert.Equal(t, got.Version+1, touched.Version)
+	})
+}
+
 func TestUserRepository_Delete(t *testing.T) {
 	repo, cleanup := setupTest(t)
 	defer cleanup()
@@ -484,6 +705,15 @@ func TestUserRepository_Search(t *testing.T) {
 			wantCount: 2,
 			wantErr:   nil,
 		},
+		{
+			name:      "offset past the end keeps total",
+			query:     "test",
+			offset:    5,
+			pageSize:  10,
+			wantUsers: []*models.User{},
+			wantCount: 2,
+			wantErr:   nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -509,6 +739,197 @@ func TestUserRepository_Search(t *testing.T) {
 	}
 }
 
+func TestUserRepository_SearchAfter(t *testing.T) {
+	repo, cleanup := setupTest(t)
+	defer cleanup()
+
+	for _, username := range []string{"testuser3", "TestUser1", "anotheruser", "testuser2"} {
+		_, err := repo.Create(context.Background(), &models.User{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "password123",
+		})
+		require.NoError(t, err)
+	}
+
+	var (
+		cursor    *models.SearchCursor
+		usernames []string
+	)
+	for {
+		page, total, err := repo.SearchAfter(context.Background(), "test", cursor, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		if len(page) == 0 {
+			break
+		}
+		for _, hit := range page {
+			usernames = append(usernames, hit.User.Username)
+		}
+		cursor = &page[len(page)-1].Cursor
+		assert.Equal(t, 3, cursor.Total)
+	}
+
+	assert.Equal(t, []string{"TestUser1", "testuser2", "testuser3"}, usernames)
+
+	t.Run("pages follow the offset order", func(t *testing.T) {
+		users, _, err := repo.Search(context.Background(), "test", 0, 10)
+		require.NoError(t, err)
+		offsetUsernames := make([]string, 0, len(users))
+		for _, user := range users {
+			offsetUsernames = append(offsetUsernames, user.Username)
+		}
+		assert.Equal(t, offsetUsernames, usernames)
+	})
+
+	t.Run("later pages report the carried total", func(t *testing.T) {
+		// Ranked above every prefix match, so all of them follow it.
+		page, total, err := repo.SearchAfter(context.Background(), "test",
+			&models.SearchCursor{Rank: 3, Total: 7}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 7, total)
+		require.Len(t, page, 3)
+		assert.Equal(t, 7, page[0].Cursor.Total)
+	})
+}
+
+func TestUserRepository_SearchRanking(t *testing.T) {
+	repo, cleanup := setupTest(t)
+	defer cleanup()
+
+	fullName := "Alice Liddell"
+	bio := "photographer from london"
+	for _, user := range []*models.User{
+		{Username: "malice", Email: "malice@example.com"},
+		{Username: "alicewonder", Email: "wonder@example.com"},
+		{Username: "alice", Email: "alice@example.com"},
+		{Username: "liddell", Email: "l@example.com", FullName: &fullName},
+		{Username: "bob", Email: "bob@example.com", Bio: &bio},
+		{Username: "carol", Email: "carol@example.com"},
+	} {
+		user.Password = "password123"
+		_, err := repo.Create(context.Background(), user)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		wantUsernames []string
+	}{
+		{
+			name:          "exact match first, then prefix, then similarity",
+			query:         "alice",
+			wantUsernames: []string{"alice", "alicewonder", "malice", "liddell"},
+		},
+		{
+			name:          "typo tolerance",
+			query:         "alise",
+			wantUsernames: []string{"alice"},
+		},
+		{
+			name:          "full text match in bio",
+			query:         "london",
+			wantUsernames: []string{"bob"},
+		},
+		{
+			name:          "email substring",
+			query:         "wonder@",
+			wantUsernames: []string{"alicewonder"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, total, err := repo.Search(context.Background(), tt.query, 0, 10)
+			require.NoError(t, err)
+
+			usernames := make([]string, 0, len(users))
+			for _, user := range users {
+				usernames = append(usernames, user.Username)
+			}
+			assert.Equal(t, tt.wantUsernames, usernames)
+			assert.Equal(t, len(tt.wantUsernames), total)
+		})
+	}
+}
+
+func TestUserRepository_AutocompleteUsernames(t *testing.T) {
+	repo, cleanup := setupTest(t)
+	defer cleanup()
+
+	for _, username := range []string{"alice", "Alison", "alfred", "bob", "ali_baba"} {
+		_, err := repo.Create(context.Background(), &models.User{
+			Username: username,
+			Email:    username + "@example.com",
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{
+			name:   "case-insensitive prefix in lowercase order",
+			prefix: "ALI",
+			limit:  10,
+			want:   []string{"ali_baba", "alice", "Alison"},
+		},
+		{
+			name:   "limit applied",
+			prefix: "al",
+			limit:  2,
+			want:   []string{"alfred", "ali_baba"},
+		},
+		{
+			name:   "no matches",
+			prefix: "zed",
+			limit:  10,
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.AutocompleteUsernames(context.Background(), tt.prefix, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUserRepository_ListUsernames(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repo user_repository.UserRepository) {
+		for _, username := range []string{"carol", "alice", "bob"} {
+			_, err := repo.Create(context.Background(), &models.User{
+				Username: username,
+				Email:    username + "@example.com",
+			})
+			require.NoError(t, err)
+		}
+
+		var (
+			afterID   int64
+			usernames []string
+		)
+		for {
+			page, lastID, err := repo.ListUsernames(context.Background(), afterID, 2)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				assert.Equal(t, afterID, lastID)
+				break
+			}
+			usernames = append(usernames, page...)
+			afterID = lastID
+		}
+
+		assert.Equal(t, []string{"carol", "alice", "bob"}, usernames, "usernames are listed in id order")
+	})
+}
+
 func TestUserRepository_UpdatePassword(t *testing.T) {
 	repo, cleanup := setupTest(t)
 	defer cleanup()
@@ -551,7 +972,7 @@ func TestUserRepository_UpdatePassword(t *testing.T) {
 				assert.Equal(t, tt.wantErr, err)
 			} else {
 				assert.NoError(t, err)
-				got, err := repo.GetByID(context.Background(), tt.id)
+				got, err := repo.GetCredentialsByID(context.Background(), tt.id)
 				assert.NoError(t, err)
 				assert.Equal(t, tt.password, got.Password)
 			}
@@ -609,3 +1030,7 @@ func TestUserRepository_UpdateAvatar(t *testing.T) {
 		})
 	}
 }
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_users_username_lower_pattern ON users (lower(username) text_pattern_ops);
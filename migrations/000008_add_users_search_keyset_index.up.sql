@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_users_username_lower_id ON users ((lower(username) COLLATE "C"), id);
@@ -22,6 +22,66 @@ func (_m *UserRepository) EXPECT() *UserRepository_Expecter {
 	return &UserRepository_Expecter{mock: &_m.Mock}
 }
 
+// AutocompleteUsernames provides a mock function with given fields: ctx, prefix, limit
+func (_m *UserRepository) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AutocompleteUsernames")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]string, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []string); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_AutocompleteUsernames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AutocompleteUsernames'
+type UserRepository_AutocompleteUsernames_Call struct {
+	*mock.Call
+}
+
+// AutocompleteUsernames is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *UserRepository_Expecter) AutocompleteUsernames(ctx interface{}, prefix interface{}, limit interface{}) *UserRepository_AutocompleteUsernames_Call {
+	return &UserRepository_AutocompleteUsernames_Call{Call: _e.mock.On("AutocompleteUsernames", ctx, prefix, limit)}
+}
+
+func (_c *UserRepository_AutocompleteUsernames_Call) Run(run func(ctx context.Context, prefix string, limit int)) *UserRepository_AutocompleteUsernames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *UserRepository_AutocompleteUsernames_Call) Return(_a0 []string, _a1 error) *UserRepository_AutocompleteUsernames_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_AutocompleteUsernames_Call) RunAndReturn(run func(context.Context, string, int) ([]string, error)) *UserRepository_AutocompleteUsernames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function with given fields: ctx, user
 func (_m *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	ret := _m.Called(ctx, user)
@@ -246,6 +306,65 @@ func (_c *UserRepository_GetByID_Call) RunAndReturn(run func(context.Context, in
 	return _c
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]*models.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []*models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]*models.User, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*models.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIDs'
+type UserRepository_GetByIDs_Call struct {
+	*mock.Call
+}
+
+// GetByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []int64
+func (_e *UserRepository_Expecter) GetByIDs(ctx interface{}, ids interface{}) *UserRepository_GetByIDs_Call {
+	return &UserRepository_GetByIDs_Call{Call: _e.mock.On("GetByIDs", ctx, ids)}
+}
+
+func (_c *UserRepository_GetByIDs_Call) Run(run func(ctx context.Context, ids []int64)) *UserRepository_GetByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetByIDs_Call) Return(_a0 []*models.User, _a1 error) *UserRepository_GetByIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetByIDs_Call) RunAndReturn(run func(context.Context, []int64) ([]*models.User, error)) *UserRepository_GetByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByUsername provides a mock function with given fields: ctx, username
 func (_m *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	ret := _m.Called(ctx, username)
@@ -305,6 +424,309 @@ func (_c *UserRepository_GetByUsername_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
+// GetCredentialsByEmail provides a mock function with given fields: ctx, email
+func (_m *UserRepository) GetCredentialsByEmail(ctx context.Context, email string) (*models.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredentialsByEmail")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetCredentialsByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredentialsByEmail'
+type UserRepository_GetCredentialsByEmail_Call struct {
+	*mock.Call
+}
+
+// GetCredentialsByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *UserRepository_Expecter) GetCredentialsByEmail(ctx interface{}, email interface{}) *UserRepository_GetCredentialsByEmail_Call {
+	return &UserRepository_GetCredentialsByEmail_Call{Call: _e.mock.On("GetCredentialsByEmail", ctx, email)}
+}
+
+func (_c *UserRepository_GetCredentialsByEmail_Call) Run(run func(ctx context.Context, email string)) *UserRepository_GetCredentialsByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByEmail_Call) Return(_a0 *models.User, _a1 error) *UserRepository_GetCredentialsByEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByEmail_Call) RunAndReturn(run func(context.Context, string) (*models.User, error)) *UserRepository_GetCredentialsByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCredentialsByID provides a mock function with given fields: ctx, id
+func (_m *UserRepository) GetCredentialsByID(ctx context.Context, id int64) (*models.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredentialsByID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetCredentialsByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredentialsByID'
+type UserRepository_GetCredentialsByID_Call struct {
+	*mock.Call
+}
+
+// GetCredentialsByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *UserRepository_Expecter) GetCredentialsByID(ctx interface{}, id interface{}) *UserRepository_GetCredentialsByID_Call {
+	return &UserRepository_GetCredentialsByID_Call{Call: _e.mock.On("GetCredentialsByID", ctx, id)}
+}
+
+func (_c *UserRepository_GetCredentialsByID_Call) Run(run func(ctx context.Context, id int64)) *UserRepository_GetCredentialsByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByID_Call) Return(_a0 *models.User, _a1 error) *UserRepository_GetCredentialsByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByID_Call) RunAndReturn(run func(context.Context, int64) (*models.User, error)) *UserRepository_GetCredentialsByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCredentialsByIDForUpdate provides a mock function with given fields: ctx, id
+func (_m *UserRepository) GetCredentialsByIDForUpdate(ctx context.Context, id int64) (*models.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredentialsByIDForUpdate")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*models.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *models.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetCredentialsByIDForUpdate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredentialsByIDForUpdate'
+type UserRepository_GetCredentialsByIDForUpdate_Call struct {
+	*mock.Call
+}
+
+// GetCredentialsByIDForUpdate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *UserRepository_Expecter) GetCredentialsByIDForUpdate(ctx interface{}, id interface{}) *UserRepository_GetCredentialsByIDForUpdate_Call {
+	return &UserRepository_GetCredentialsByIDForUpdate_Call{Call: _e.mock.On("GetCredentialsByIDForUpdate", ctx, id)}
+}
+
+func (_c *UserRepository_GetCredentialsByIDForUpdate_Call) Run(run func(ctx context.Context, id int64)) *UserRepository_GetCredentialsByIDForUpdate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByIDForUpdate_Call) Return(_a0 *models.User, _a1 error) *UserRepository_GetCredentialsByIDForUpdate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByIDForUpdate_Call) RunAndReturn(run func(context.Context, int64) (*models.User, error)) *UserRepository_GetCredentialsByIDForUpdate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCredentialsByUsername provides a mock function with given fields: ctx, username
+func (_m *UserRepository) GetCredentialsByUsername(ctx context.Context, username string) (*models.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredentialsByUsername")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserRepository_GetCredentialsByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredentialsByUsername'
+type UserRepository_GetCredentialsByUsername_Call struct {
+	*mock.Call
+}
+
+// GetCredentialsByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *UserRepository_Expecter) GetCredentialsByUsername(ctx interface{}, username interface{}) *UserRepository_GetCredentialsByUsername_Call {
+	return &UserRepository_GetCredentialsByUsername_Call{Call: _e.mock.On("GetCredentialsByUsername", ctx, username)}
+}
+
+func (_c *UserRepository_GetCredentialsByUsername_Call) Run(run func(ctx context.Context, username string)) *UserRepository_GetCredentialsByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByUsername_Call) Return(_a0 *models.User, _a1 error) *UserRepository_GetCredentialsByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserRepository_GetCredentialsByUsername_Call) RunAndReturn(run func(context.Context, string) (*models.User, error)) *UserRepository_GetCredentialsByUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsernames provides a mock function with given fields: ctx, afterID, limit
+func (_m *UserRepository) ListUsernames(ctx context.Context, afterID int64, limit int) ([]string, int64, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsernames")
+	}
+
+	var r0 []string
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]string, int64, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []string); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) int64); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int) error); ok {
+		r2 = rf(ctx, afterID, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserRepository_ListUsernames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsernames'
+type UserRepository_ListUsernames_Call struct {
+	*mock.Call
+}
+
+// ListUsernames is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID int64
+//   - limit int
+func (_e *UserRepository_Expecter) ListUsernames(ctx interface{}, afterID interface{}, limit interface{}) *UserRepository_ListUsernames_Call {
+	return &UserRepository_ListUsernames_Call{Call: _e.mock.On("ListUsernames", ctx, afterID, limit)}
+}
+
+func (_c *UserRepository_ListUsernames_Call) Run(run func(ctx context.Context, afterID int64, limit int)) *UserRepository_ListUsernames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *UserRepository_ListUsernames_Call) Return(_a0 []string, _a1 int64, _a2 error) *UserRepository_ListUsernames_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserRepository_ListUsernames_Call) RunAndReturn(run func(context.Context, int64, int) ([]string, int64, error)) *UserRepository_ListUsernames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Search provides a mock function with given fields: ctx, searchQuery, offset, pageSize
 func (_m *UserRepository) Search(ctx context.Context, searchQuery string, offset int, pageSize int) ([]*models.User, int, error) {
 	ret := _m.Called(ctx, searchQuery, offset, pageSize)
@@ -373,9 +795,77 @@ func (_c *UserRepository_Search_Call) RunAndReturn(run func(context.Context, str
 	return _c
 }
 
-// Update provides a mock function with given fields: ctx, user
-func (_m *UserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	ret := _m.Called(ctx, user)
+// SearchAfter provides a mock function with given fields: ctx, searchQuery, cursor, pageSize
+func (_m *UserRepository) SearchAfter(ctx context.Context, searchQuery string, cursor *models.SearchCursor, pageSize int) ([]*models.SearchHit, int, error) {
+	ret := _m.Called(ctx, searchQuery, cursor, pageSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchAfter")
+	}
+
+	var r0 []*models.SearchHit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.SearchCursor, int) ([]*models.SearchHit, int, error)); ok {
+		return rf(ctx, searchQuery, cursor, pageSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.SearchCursor, int) []*models.SearchHit); ok {
+		r0 = rf(ctx, searchQuery, cursor, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *models.SearchCursor, int) int); ok {
+		r1 = rf(ctx, searchQuery, cursor, pageSize)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *models.SearchCursor, int) error); ok {
+		r2 = rf(ctx, searchQuery, cursor, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserRepository_SearchAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchAfter'
+type UserRepository_SearchAfter_Call struct {
+	*mock.Call
+}
+
+// SearchAfter is a helper method to define mock.On call
+//   - ctx context.Context
+//   - searchQuery string
+//   - cursor *models.SearchCursor
+//   - pageSize int
+func (_e *UserRepository_Expecter) SearchAfter(ctx interface{}, searchQuery interface{}, cursor interface{}, pageSize interface{}) *UserRepository_SearchAfter_Call {
+	return &UserRepository_SearchAfter_Call{Call: _e.mock.On("SearchAfter", ctx, searchQuery, cursor, pageSize)}
+}
+
+func (_c *UserRepository_SearchAfter_Call) Run(run func(ctx context.Context, searchQuery string, cursor *models.SearchCursor, pageSize int)) *UserRepository_SearchAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*models.SearchCursor), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *UserRepository_SearchAfter_Call) Return(_a0 []*models.SearchHit, _a1 int, _a2 error) *UserRepository_SearchAfter_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserRepository_SearchAfter_Call) RunAndReturn(run func(context.Context, string, *models.SearchCursor, int) ([]*models.SearchHit, int, error)) *UserRepository_SearchAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, user, fields
+func (_m *UserRepository) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
+	ret := _m.Called(ctx, user, fields)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
@@ -383,19 +873,19 @@ func (_m *UserRepository) Update(ctx context.Context, user *models.User) (*model
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
-		return rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, models.UserFields) (*models.User, error)); ok {
+		return rf(ctx, user, fields)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, models.UserFields) *models.User); ok {
+		r0 = rf(ctx, user, fields)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(ctx, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, models.UserFields) error); ok {
+		r1 = rf(ctx, user, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -411,13 +901,14 @@ type UserRepository_Update_Call struct {
 // Update is a helper method to define mock.On call
 //   - ctx context.Context
 //   - user *models.User
-func (_e *UserRepository_Expecter) Update(ctx interface{}, user interface{}) *UserRepository_Update_Call {
-	return &UserRepository_Update_Call{Call: _e.mock.On("Update", ctx, user)}
+//   - fields models.UserFields
+func (_e *UserRepository_Expecter) Update(ctx interface{}, user interface{}, fields interface{}) *UserRepository_Update_Call {
+	return &UserRepository_Update_Call{Call: _e.mock.On("Update", ctx, user, fields)}
 }
 
-func (_c *UserRepository_Update_Call) Run(run func(ctx context.Context, user *models.User)) *UserRepository_Update_Call {
+func (_c *UserRepository_Update_Call) Run(run func(ctx context.Context, user *models.User, fields models.UserFields)) *UserRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.User))
+		run(args[0].(context.Context), args[1].(*models.User), args[2].(models.UserFields))
 	})
 	return _c
 }
@@ -427,7 +918,7 @@ func (_c *UserRepository_Update_Call) Return(_a0 *models.User, _a1 error) *UserR
 	return _c
 }
 
-func (_c *UserRepository_Update_Call) RunAndReturn(run func(context.Context, *models.User) (*models.User, error)) *UserRepository_Update_Call {
+func (_c *UserRepository_Update_Call) RunAndReturn(run func(context.Context, *models.User, models.UserFields) (*models.User, error)) *UserRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
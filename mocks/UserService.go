@@ -23,6 +23,66 @@ func (_m *UserService) EXPECT() *UserService_Expecter {
 	return &UserService_Expecter{mock: &_m.Mock}
 }
 
+// AutocompleteUsernames provides a mock function with given fields: ctx, prefix, limit
+func (_m *UserService) AutocompleteUsernames(ctx context.Context, prefix string, limit int) ([]string, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AutocompleteUsernames")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]string, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []string); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserService_AutocompleteUsernames_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AutocompleteUsernames'
+type UserService_AutocompleteUsernames_Call struct {
+	*mock.Call
+}
+
+// AutocompleteUsernames is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *UserService_Expecter) AutocompleteUsernames(ctx interface{}, prefix interface{}, limit interface{}) *UserService_AutocompleteUsernames_Call {
+	return &UserService_AutocompleteUsernames_Call{Call: _e.mock.On("AutocompleteUsernames", ctx, prefix, limit)}
+}
+
+func (_c *UserService_AutocompleteUsernames_Call) Run(run func(ctx context.Context, prefix string, limit int)) *UserService_AutocompleteUsernames_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *UserService_AutocompleteUsernames_Call) Return(_a0 []string, _a1 error) *UserService_AutocompleteUsernames_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserService_AutocompleteUsernames_Call) RunAndReturn(run func(context.Context, string, int) ([]string, error)) *UserService_AutocompleteUsernames_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function with given fields: ctx, user
 func (_m *UserService) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	ret := _m.Called(ctx, user)
@@ -306,9 +366,77 @@ func (_c *UserService_GetByUsername_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
-// Search provides a mock function with given fields: ctx, query, page, limit
-func (_m *UserService) Search(ctx context.Context, query string, page int, limit int) ([]*models.User, int, error) {
-	ret := _m.Called(ctx, query, page, limit)
+// GetUsersByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserService) GetUsersByIDs(ctx context.Context, ids []int64) ([]*models.User, []int64, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
+	var r0 []*models.User
+	var r1 []int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]*models.User, []int64, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*models.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) []int64); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []int64) error); ok {
+		r2 = rf(ctx, ids)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserService_GetUsersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsersByIDs'
+type UserService_GetUsersByIDs_Call struct {
+	*mock.Call
+}
+
+// GetUsersByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []int64
+func (_e *UserService_Expecter) GetUsersByIDs(ctx interface{}, ids interface{}) *UserService_GetUsersByIDs_Call {
+	return &UserService_GetUsersByIDs_Call{Call: _e.mock.On("GetUsersByIDs", ctx, ids)}
+}
+
+func (_c *UserService_GetUsersByIDs_Call) Run(run func(ctx context.Context, ids []int64)) *UserService_GetUsersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *UserService_GetUsersByIDs_Call) Return(_a0 []*models.User, _a1 []int64, _a2 error) *UserService_GetUsersByIDs_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserService_GetUsersByIDs_Call) RunAndReturn(run func(context.Context, []int64) ([]*models.User, []int64, error)) *UserService_GetUsersByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Search provides a mock function with given fields: ctx, query, offset, limit
+func (_m *UserService) Search(ctx context.Context, query string, offset int, limit int) ([]*models.User, int, error) {
+	ret := _m.Called(ctx, query, offset, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Search")
@@ -318,10 +446,10 @@ func (_m *UserService) Search(ctx context.Context, query string, page int, limit
 	var r1 int
 	var r2 error
 	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) ([]*models.User, int, error)); ok {
-		return rf(ctx, query, page, limit)
+		return rf(ctx, query, offset, limit)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*models.User); ok {
-		r0 = rf(ctx, query, page, limit)
+		r0 = rf(ctx, query, offset, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.User)
@@ -329,13 +457,13 @@ func (_m *UserService) Search(ctx context.Context, query string, page int, limit
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) int); ok {
-		r1 = rf(ctx, query, page, limit)
+		r1 = rf(ctx, query, offset, limit)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
 	if rf, ok := ret.Get(2).(func(context.Context, string, int, int) error); ok {
-		r2 = rf(ctx, query, page, limit)
+		r2 = rf(ctx, query, offset, limit)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -351,13 +479,13 @@ type UserService_Search_Call struct {
 // Search is a helper method to define mock.On call
 //   - ctx context.Context
 //   - query string
-//   - page int
+//   - offset int
 //   - limit int
-func (_e *UserService_Expecter) Search(ctx interface{}, query interface{}, page interface{}, limit interface{}) *UserService_Search_Call {
-	return &UserService_Search_Call{Call: _e.mock.On("Search", ctx, query, page, limit)}
+func (_e *UserService_Expecter) Search(ctx interface{}, query interface{}, offset interface{}, limit interface{}) *UserService_Search_Call {
+	return &UserService_Search_Call{Call: _e.mock.On("Search", ctx, query, offset, limit)}
 }
 
-func (_c *UserService_Search_Call) Run(run func(ctx context.Context, query string, page int, limit int)) *UserService_Search_Call {
+func (_c *UserService_Search_Call) Run(run func(ctx context.Context, query string, offset int, limit int)) *UserService_Search_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(int))
 	})
@@ -374,9 +502,84 @@ func (_c *UserService_Search_Call) RunAndReturn(run func(context.Context, string
 	return _c
 }
 
-// Update provides a mock function with given fields: ctx, user
-func (_m *UserService) Update(ctx context.Context, user *models.User) (*models.User, error) {
-	ret := _m.Called(ctx, user)
+// SearchPage provides a mock function with given fields: ctx, query, pageToken, limit
+func (_m *UserService) SearchPage(ctx context.Context, query string, pageToken string, limit int) ([]*models.User, int, string, error) {
+	ret := _m.Called(ctx, query, pageToken, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchPage")
+	}
+
+	var r0 []*models.User
+	var r1 int
+	var r2 string
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]*models.User, int, string, error)); ok {
+		return rf(ctx, query, pageToken, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []*models.User); ok {
+		r0 = rf(ctx, query, pageToken, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) int); ok {
+		r1 = rf(ctx, query, pageToken, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, int) string); ok {
+		r2 = rf(ctx, query, pageToken, limit)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string, string, int) error); ok {
+		r3 = rf(ctx, query, pageToken, limit)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// UserService_SearchPage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchPage'
+type UserService_SearchPage_Call struct {
+	*mock.Call
+}
+
+// SearchPage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - pageToken string
+//   - limit int
+func (_e *UserService_Expecter) SearchPage(ctx interface{}, query interface{}, pageToken interface{}, limit interface{}) *UserService_SearchPage_Call {
+	return &UserService_SearchPage_Call{Call: _e.mock.On("SearchPage", ctx, query, pageToken, limit)}
+}
+
+func (_c *UserService_SearchPage_Call) Run(run func(ctx context.Context, query string, pageToken string, limit int)) *UserService_SearchPage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *UserService_SearchPage_Call) Return(_a0 []*models.User, _a1 int, _a2 string, _a3 error) *UserService_SearchPage_Call {
+	_c.Call.Return(_a0, _a1, _a2, _a3)
+	return _c
+}
+
+func (_c *UserService_SearchPage_Call) RunAndReturn(run func(context.Context, string, string, int) ([]*models.User, int, string, error)) *UserService_SearchPage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function with given fields: ctx, user, fields
+func (_m *UserService) Update(ctx context.Context, user *models.User, fields models.UserFields) (*models.User, error) {
+	ret := _m.Called(ctx, user, fields)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
@@ -384,19 +587,19 @@ func (_m *UserService) Update(ctx context.Context, user *models.User) (*models.U
 
 	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) (*models.User, error)); ok {
-		return rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, models.UserFields) (*models.User, error)); ok {
+		return rf(ctx, user, fields)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, models.UserFields) *models.User); ok {
+		r0 = rf(ctx, user, fields)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(ctx, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, models.UserFields) error); ok {
+		r1 = rf(ctx, user, fields)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -412,13 +615,14 @@ type UserService_Update_Call struct {
 // Update is a helper method to define mock.On call
 //   - ctx context.Context
 //   - user *models.User
-func (_e *UserService_Expecter) Update(ctx interface{}, user interface{}) *UserService_Update_Call {
-	return &UserService_Update_Call{Call: _e.mock.On("Update", ctx, user)}
+//   - fields models.UserFields
+func (_e *UserService_Expecter) Update(ctx interface{}, user interface{}, fields interface{}) *UserService_Update_Call {
+	return &UserService_Update_Call{Call: _e.mock.On("Update", ctx, user, fields)}
 }
 
-func (_c *UserService_Update_Call) Run(run func(ctx context.Context, user *models.User)) *UserService_Update_Call {
+func (_c *UserService_Update_Call) Run(run func(ctx context.Context, user *models.User, fields models.UserFields)) *UserService_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.User))
+		run(args[0].(context.Context), args[1].(*models.User), args[2].(models.UserFields))
 	})
 	return _c
 }
@@ -428,7 +632,7 @@ func (_c *UserService_Update_Call) Return(_a0 *models.User, _a1 error) *UserServ
 	return _c
 }
 
-func (_c *UserService_Update_Call) RunAndReturn(run func(context.Context, *models.User) (*models.User, error)) *UserService_Update_Call {
+func (_c *UserService_Update_Call) RunAndReturn(run func(context.Context, *models.User, models.UserFields) (*models.User, error)) *UserService_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -530,6 +734,66 @@ func (_c *UserService_UpdatePassword_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// VerifyCredentials provides a mock function with given fields: ctx, login, password
+func (_m *UserService) VerifyCredentials(ctx context.Context, login string, password string) (*models.User, error) {
+	ret := _m.Called(ctx, login, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyCredentials")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.User, error)); ok {
+		return rf(ctx, login, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = rf(ctx, login, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, login, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserService_VerifyCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyCredentials'
+type UserService_VerifyCredentials_Call struct {
+	*mock.Call
+}
+
+// VerifyCredentials is a helper method to define mock.On call
+//   - ctx context.Context
+//   - login string
+//   - password string
+func (_e *UserService_Expecter) VerifyCredentials(ctx interface{}, login interface{}, password interface{}) *UserService_VerifyCredentials_Call {
+	return &UserService_VerifyCredentials_Call{Call: _e.mock.On("VerifyCredentials", ctx, login, password)}
+}
+
+func (_c *UserService_VerifyCredentials_Call) Run(run func(ctx context.Context, login string, password string)) *UserService_VerifyCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_VerifyCredentials_Call) Return(_a0 *models.User, _a1 error) *UserService_VerifyCredentials_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserService_VerifyCredentials_Call) RunAndReturn(run func(context.Context, string, string) (*models.User, error)) *UserService_VerifyCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {